@@ -0,0 +1,116 @@
+package pi42
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// BalanceField selects the balance a low-balance alert watches
+type BalanceField string
+
+const (
+	// BalanceWithdrawable is the balance free for new orders and withdrawals
+	BalanceWithdrawable BalanceField = "withdrawable"
+	// BalanceMargin is the wallet balance plus unrealized PnL
+	BalanceMargin BalanceField = "margin"
+)
+
+// LowBalanceAlert reports a balance crossing its threshold
+type LowBalanceAlert struct {
+	Field       BalanceField `json:"field"`
+	MarginAsset string       `json:"marginAsset"`
+	Threshold   float64      `json:"threshold"`
+	Balance     float64      `json:"balance"`
+	// True when the balance rose back to or above the threshold
+	Recovered bool      `json:"recovered"`
+	Time      time.Time `json:"time"`
+}
+
+// balanceAlert is a threshold registered with OnLowBalance
+type balanceAlert struct {
+	field     BalanceField
+	threshold float64
+	callback  func(LowBalanceAlert)
+	// Whether the balance is currently below the threshold
+	low bool
+}
+
+// OnLowBalance registers a callback invoked once when the field drops below
+// threshold and once when it recovers. Balances are checked on every stream
+// update and reconciliation, so an alert fires before orders start failing
+// even when the change is too small to be reported by OnChange.
+func (w *BalanceWatcher) OnLowBalance(field BalanceField, threshold float64, callback func(LowBalanceAlert)) error {
+	if field != BalanceWithdrawable && field != BalanceMargin {
+		return fmt.Errorf("invalid balance field %q (expected %s or %s)", field, BalanceWithdrawable, BalanceMargin)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.alerts = append(w.alerts, &balanceAlert{field: field, threshold: threshold, callback: callback})
+	return nil
+}
+
+// checkAlerts fires the alerts whose threshold was crossed
+func (w *BalanceWatcher) checkAlerts(balances FuturesWalletBalances) {
+	var fired []func()
+
+	w.mu.Lock()
+	for _, alert := range w.alerts {
+		balance := balances.WithdrawableBalance
+		if alert.field == BalanceMargin {
+			balance = balances.WalletBalance + balances.UnrealisedPnl()
+		}
+
+		low := balance < alert.threshold
+		if low == alert.low {
+			continue
+		}
+		alert.low = low
+
+		event := LowBalanceAlert{
+			Field:       alert.field,
+			MarginAsset: balances.MarginAsset,
+			Threshold:   alert.threshold,
+			Balance:     balance,
+			Recovered:   !low,
+			Time:        time.Now(),
+		}
+		callback := alert.callback
+		fired = append(fired, func() { callback(event) })
+	}
+	w.mu.Unlock()
+
+	for _, fire := range fired {
+		fire()
+	}
+}
+
+// WebhookAlert returns an OnLowBalance callback that posts each alert as
+// JSON to url. Requests are sent in the background; failures are logged.
+func WebhookAlert(url string) func(LowBalanceAlert) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	return func(alert LowBalanceAlert) {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			logger().Errorf("Could not encode balance alert: %v", err)
+			return
+		}
+
+		go func() {
+			resp, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				logger().Warnf("Could not deliver balance alert: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				logger().Warnf("Balance alert webhook returned %s", resp.Status)
+			}
+		}()
+	}
+}
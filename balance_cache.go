@@ -0,0 +1,154 @@
+package pi42
+
+import (
+	"strings"
+	"sync"
+)
+
+// BalanceCache mirrors the futures and funding wallet balances of a margin
+// asset. It is seeded from the wallet endpoints and kept current by
+// balanceUpdate events of the user data stream.
+type BalanceCache struct {
+	client *Client
+
+	// Margin asset whose wallets are cached, e.g. INR
+	marginAsset string
+	// Latest wallet details
+	futures FuturesWalletResponse
+	funding FundingWalletResponse
+	// Callbacks invoked with the futures wallet after each balance update
+	callbacks []func(FuturesWalletResponse)
+	// Whether the cache is registered with the user stream
+	started bool
+	// Mutex for thread-safe access to balances and callbacks
+	mu sync.RWMutex
+}
+
+// NewBalanceCache creates a balance cache for a margin asset fed by the
+// client's user stream. An empty margin asset defaults to INR.
+func NewBalanceCache(client *Client, marginAsset string) *BalanceCache {
+	if marginAsset == "" {
+		marginAsset = "INR"
+	}
+	return &BalanceCache{
+		client:      client,
+		marginAsset: strings.ToUpper(marginAsset),
+	}
+}
+
+// Start registers the cache with the user stream and loads both wallets from
+// the REST API. The user stream must be connected separately with Connect.
+func (bc *BalanceCache) Start() error {
+	bc.mu.Lock()
+	started := bc.started
+	bc.started = true
+	bc.mu.Unlock()
+
+	if !started {
+		bc.client.UserStream.OnBalanceUpdate(func(balance *BalanceUpdateEvent) {
+			bc.apply(balance.FuturesWalletResponse)
+		})
+	}
+	return bc.Refresh()
+}
+
+// Refresh reloads both wallets from the REST API
+func (bc *BalanceCache) Refresh() error {
+	futures, err := bc.client.Wallet.FuturesWalletDetails(bc.marginAsset)
+	if err != nil {
+		return err
+	}
+	funding, err := bc.client.Wallet.FundingWalletDetails(bc.marginAsset)
+	if err != nil {
+		return err
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.futures = *futures
+	bc.funding = *funding
+	return nil
+}
+
+// OnChange registers a callback invoked with the futures wallet after each
+// balance update
+func (bc *BalanceCache) OnChange(callback func(FuturesWalletResponse)) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.callbacks = append(bc.callbacks, callback)
+}
+
+// Futures returns the latest futures wallet details
+func (bc *BalanceCache) Futures() FuturesWalletResponse {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.futures
+}
+
+// Funding returns the latest funding wallet details
+func (bc *BalanceCache) Funding() FundingWalletResponse {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.funding
+}
+
+// WalletBalance returns the futures wallet balance
+func (bc *BalanceCache) WalletBalance() (float64, error) {
+	return parseBalance("walletBalance", bc.Futures().WalletBalance)
+}
+
+// MarginBalance returns the futures margin balance, including unrealized PnL
+func (bc *BalanceCache) MarginBalance() (float64, error) {
+	return parseBalance("marginBalance", bc.Futures().MarginBalance)
+}
+
+// WithdrawableBalance returns the futures balance available for new orders and withdrawals
+func (bc *BalanceCache) WithdrawableBalance() (float64, error) {
+	return parseBalance("withdrawableBalance", bc.Futures().WithdrawableBalance)
+}
+
+// LockedBalance returns the futures balance locked in orders and positions
+func (bc *BalanceCache) LockedBalance() (float64, error) {
+	return parseBalance("lockedBalance", bc.Futures().LockedBalance)
+}
+
+// UnrealisedPnl returns the combined cross and isolated unrealized PnL
+func (bc *BalanceCache) UnrealisedPnl() (float64, error) {
+	futures := bc.Futures()
+	cross, err := parseBalance("unrealisedPnlCross", futures.UnrealisedPnlCross)
+	if err != nil {
+		return 0, err
+	}
+	isolated, err := parseBalance("unrealisedPnlIsolated", futures.UnrealisedPnlIsolated)
+	if err != nil {
+		return 0, err
+	}
+	return cross + isolated, nil
+}
+
+// FundingBalance returns the funding wallet balance
+func (bc *BalanceCache) FundingBalance() (float64, error) {
+	return parseBalance("funding walletBalance", bc.Funding().WalletBalance)
+}
+
+// apply stores a futures wallet update and notifies callbacks. Updates for
+// other margin assets are ignored.
+func (bc *BalanceCache) apply(futures FuturesWalletResponse) {
+	if futures.MarginAsset != "" && !strings.EqualFold(futures.MarginAsset, bc.marginAsset) {
+		return
+	}
+
+	bc.mu.Lock()
+	bc.futures = futures
+	callbacks := append([]func(FuturesWalletResponse){}, bc.callbacks...)
+	bc.mu.Unlock()
+
+	// Invoke callbacks outside the lock so they may query the cache
+	for _, callback := range callbacks {
+		callback(futures)
+	}
+}
@@ -0,0 +1,154 @@
+package pi42
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultBalancePollInterval is how often BalanceWatcher reconciles with
+// the REST API when no interval is configured
+const defaultBalancePollInterval = time.Minute
+
+// Sources of a BalanceChange
+const (
+	BalanceSourceStream = "stream"
+	BalanceSourcePoll   = "poll"
+)
+
+// BalanceWatcherConfig configures BalanceWatcher. A change is reported
+// when the total or available balance moved by at least Threshold or
+// ThresholdPercent since the last report; with neither set, every change
+// is reported.
+type BalanceWatcherConfig struct {
+	Threshold        float64 // Absolute change in the margin asset
+	ThresholdPercent float64 // Change in percent of the previously reported balance
+	// Interval of the REST reconciliation; defaults to one minute
+	PollInterval time.Duration
+}
+
+// BalanceChange reports a significant change of the futures wallet
+type BalanceChange struct {
+	Source   string // BalanceSourceStream or BalanceSourcePoll
+	Previous FuturesWalletBalances
+	Current  FuturesWalletBalances
+	// Change of the wallet balance and of the withdrawable balance
+	TotalDelta     float64
+	AvailableDelta float64
+}
+
+// BalanceWatcher reports significant changes of the futures wallet
+// balances. It combines balanceUpdate events from the user stream with
+// periodic REST reconciliation, so changes are caught even if events are
+// missed.
+type BalanceWatcher struct {
+	cache  *BalanceCache
+	config BalanceWatcherConfig
+
+	// Balances at the last report
+	baseline    FuturesWalletBalances
+	hasBaseline bool
+	callbacks   []func(BalanceChange)
+	alerts      []*balanceAlert
+	mu          sync.Mutex
+}
+
+// NewBalanceWatcher creates a watcher over a balance cache
+func NewBalanceWatcher(cache *BalanceCache, config BalanceWatcherConfig) *BalanceWatcher {
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultBalancePollInterval
+	}
+	return &BalanceWatcher{cache: cache, config: config}
+}
+
+// OnChange registers a callback invoked for each significant balance change
+func (w *BalanceWatcher) OnChange(callback func(BalanceChange)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, callback)
+}
+
+// Run starts the balance cache and watches for changes until ctx is done.
+// Reconciliation errors are logged and retried at the next interval.
+func (w *BalanceWatcher) Run(ctx context.Context) error {
+	w.cache.OnChange(func(futures FuturesWalletResponse) {
+		w.evaluate(BalanceSourceStream, futures)
+	})
+	if err := w.cache.Start(); err != nil {
+		return err
+	}
+	w.evaluate(BalanceSourcePoll, w.cache.Futures())
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := w.cache.Refresh(); err != nil {
+				logger().Warnf("Could not reconcile wallet balance: %v", err)
+				continue
+			}
+			w.evaluate(BalanceSourcePoll, w.cache.Futures())
+		}
+	}
+}
+
+// evaluate compares balances with the last report and notifies callbacks
+// if the change is significant
+func (w *BalanceWatcher) evaluate(source string, futures FuturesWalletResponse) {
+	current, err := futures.Balances()
+	if err != nil {
+		logger().Warnf("Could not parse wallet balance: %v", err)
+		return
+	}
+	w.checkAlerts(current)
+
+	w.mu.Lock()
+	if !w.hasBaseline {
+		w.baseline = current
+		w.hasBaseline = true
+		w.mu.Unlock()
+		return
+	}
+
+	change := BalanceChange{
+		Source:         source,
+		Previous:       w.baseline,
+		Current:        current,
+		TotalDelta:     current.WalletBalance - w.baseline.WalletBalance,
+		AvailableDelta: current.WithdrawableBalance - w.baseline.WithdrawableBalance,
+	}
+	if !w.significant(change.TotalDelta, w.baseline.WalletBalance) &&
+		!w.significant(change.AvailableDelta, w.baseline.WithdrawableBalance) {
+		w.mu.Unlock()
+		return
+	}
+	w.baseline = current
+	callbacks := slices.Clone(w.callbacks)
+	w.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(change)
+	}
+}
+
+// significant reports whether delta crosses the configured thresholds
+func (w *BalanceWatcher) significant(delta, previous float64) bool {
+	if delta == 0 {
+		return false
+	}
+	if w.config.Threshold <= 0 && w.config.ThresholdPercent <= 0 {
+		return true
+	}
+	if w.config.Threshold > 0 && math.Abs(delta) >= w.config.Threshold {
+		return true
+	}
+	return w.config.ThresholdPercent > 0 && previous != 0 &&
+		math.Abs(delta/previous)*100 >= w.config.ThresholdPercent
+}
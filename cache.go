@@ -0,0 +1,56 @@
+package pi42
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheEntry holds a cached response body and its expiry time
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// responseCache is a small thread-safe TTL cache for raw response bodies.
+// Bodies are cached rather than parsed results so every caller receives
+// its own freshly decoded copy.
+type responseCache struct {
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	mu      sync.Mutex
+}
+
+// newResponseCache creates a cache whose entries expire after ttl
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// get returns the cached body for key if it has not expired
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(rc.entries, key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// set stores a body under key
+func (rc *responseCache) set(key string, data []byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries[key] = cacheEntry{
+		data:    data,
+		expires: time.Now().Add(rc.ttl),
+	}
+}
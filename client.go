@@ -8,9 +8,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -33,6 +34,28 @@ type ContractInfo struct {
 	ContractType      string
 	LiquidationFee    float64
 	Tags              []string
+	// Trading fees in percent of the notional
+	MakerFee float64
+	TakerFee float64
+	// Minimum order value in the quote asset, from the MIN_NOTIONAL filter
+	MinNotional float64
+	// Price increment from the PRICE_FILTER filter; zero if not provided,
+	// in which case prices step by the price precision
+	TickSize float64
+	// Maximum number of open orders, from the MAX_NUM_ORDERS filter
+	MaxOpenOrders int
+	// Maximum deviation of a limit price from the mark price, as a fraction
+	LimitPriceVariance float64
+	// Extra margin reserved on top of the initial margin of new orders, in percent
+	MarginBufferPercentage float64
+	// Default maintenance margin rate, in percent
+	MaintenanceMarginPercentage float64
+	// Maintenance margin brackets parsed from maintenanceMarginConfig
+	MarginTiers []MarginTier
+	// Price steps supported by the depth stream's grouping, ascending
+	DepthGroupings []float64
+	// Time between funding fee settlements; zero if unknown
+	FundingFeeInterval time.Duration
 }
 
 // Client represents the API client for Pi42
@@ -50,18 +73,47 @@ type Client struct {
 	Exchange *ExchangeAPI
 	UserData *UserDataAPI
 
-	ExchangeInfo map[string]ContractInfo
+	// Symbols normalizes and validates symbols passed to the API
+	Symbols *SymbolResolver
+
+	// WebSocket is the public market data stream client; call Connect to connect
+	WebSocket *SocketClient
+	// UserStream is the authenticated account event stream; call Connect to connect
+	UserStream *UserStream
+
+	ExchangeInfo    map[string]ContractInfo
+	ConversionRates map[string]float64
+	AssetPrecisions map[string]int
+
+	// Guards the exchange info maps, which are replaced on refresh, and
+	// the contract change callbacks
+	infoMu            sync.RWMutex
+	contractCallbacks []func(ContractChange)
+
+	// Exchange info disk cache; see NewClientWithCache
+	infoCachePath string
+	infoETag      string
+	infoFetchedAt time.Time
 }
 
 // NewClient creates a new API client instance
 func NewClient(apiKey, apiSecret string) *Client {
+	client := newClient(apiKey, apiSecret)
+	client.loadExchangeInfo()
+	return client
+}
+
+// newClient creates a client without loading exchange info
+func newClient(apiKey, apiSecret string) *Client {
 	client := &Client{
-		APIKey:       apiKey,
-		APISecret:    apiSecret,
-		BaseURL:      "https://fapi.pi42.com",
-		PublicURL:    "https://api.pi42.com",
-		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
-		ExchangeInfo: make(map[string]ContractInfo),
+		APIKey:          apiKey,
+		APISecret:       apiSecret,
+		BaseURL:         "https://fapi.pi42.com",
+		PublicURL:       "https://api.pi42.com",
+		HTTPClient:      &http.Client{Timeout: 30 * time.Second},
+		ExchangeInfo:    make(map[string]ContractInfo),
+		ConversionRates: make(map[string]float64),
+		AssetPrecisions: make(map[string]int),
 	}
 
 	// Initialize API components
@@ -71,35 +123,77 @@ func NewClient(apiKey, apiSecret string) *Client {
 	client.Wallet = NewWalletAPI(client)
 	client.Exchange = NewExchangeAPI(client)
 	client.UserData = NewUserDataAPI(client)
-	err := client.fetchExchangeInfo()
+	client.Symbols = NewSymbolResolver(client)
+	client.WebSocket = NewSocketClient()
+	client.WebSocket.market = client.Market
+	client.UserStream = NewUserStream(client)
+	return client
+}
+
+// loadExchangeInfo fetches the exchange info, logging the outcome
+func (c *Client) loadExchangeInfo() {
+	_, err := c.fetchExchangeInfo()
 	if err != nil {
-		log.Printf("Error fetching exchange info: %v", err)
+		logger().Warnf("Error fetching exchange info: %v", err)
 	} else {
-		log.Println("Exchange info loaded successfully")
+		logger().Infof("Exchange info loaded successfully")
 	}
-	return client
 }
 
-// fetchExchangeInfo loads contract specifications from the exchange
-func (c *Client) fetchExchangeInfo() error {
+// fetchExchangeInfo loads contract specifications from the exchange and
+// returns how the contracts changed since the previous load
+func (c *Client) fetchExchangeInfo() ([]ContractChange, error) {
 	endpoint := "/v1/exchange/exchangeInfo"
 
 	data, err := c.Get(endpoint, nil, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	etag := exchangeInfoETag(data)
+	c.infoMu.Lock()
+	unchanged := etag == c.infoETag
+	c.infoFetchedAt = time.Now()
+	c.infoMu.Unlock()
+	if unchanged {
+		c.saveExchangeInfoCache(data)
+		return nil, nil
+	}
+
+	changes, err := c.applyExchangeInfo(data)
+	if err != nil {
+		return nil, err
+	}
+	c.saveExchangeInfoCache(data)
+	return changes, nil
+}
+
+// applyExchangeInfo parses an exchange info response and replaces the
+// loaded contracts, returning how they changed
+func (c *Client) applyExchangeInfo(data []byte) ([]ContractChange, error) {
 	var response ExchangeInfoResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		return fmt.Errorf("error parsing exchange info response: %v", err)
+		return nil, fmt.Errorf("error parsing exchange info response: %v", err)
+	}
+	if response.ConversionRates == nil {
+		response.ConversionRates = make(map[string]float64)
+	}
+	if response.AssetPrecisions == nil {
+		response.AssetPrecisions = make(map[string]int)
 	}
 
+	exchangeInfo := make(map[string]ContractInfo, len(response.Contracts))
+
 	// Process each contract and extract the needed information
 	for _, contract := range response.Contracts {
 		// Parse precision values
 		pricePrecision, _ := strconv.Atoi(contract.PricePrecision)
 		quantityPrecision, _ := strconv.Atoi(contract.QuantityPrecision)
 		maxLeverage, _ := strconv.ParseFloat(contract.MaxLeverage, 64)
+		liquidationFee, _ := strconv.ParseFloat(contract.LiquidationFee, 64)
+		marginBuffer, _ := strconv.ParseFloat(contract.MarginBufferPercentage, 64)
+		maintMargin, _ := strconv.ParseFloat(contract.MaintenanceMarginPercentage, 64)
+		priceVariance, _ := strconv.ParseFloat(contract.LimitPriceVarAllowed, 64)
 
 		// Initialize with defaults
 		contractInfo := ContractInfo{
@@ -114,7 +208,17 @@ func (c *Client) fetchExchangeInfo() error {
 			MaxLeverage:       maxLeverage,
 			MarginAssets:      contract.MarginAssetsSupported,
 			ContractType:      contract.ContractType,
+			LiquidationFee:    liquidationFee,
 			Tags:              contract.Tags,
+			MakerFee:          contract.MakerFee,
+			TakerFee:          contract.TakerFee,
+
+			LimitPriceVariance:          priceVariance,
+			MarginBufferPercentage:      marginBuffer,
+			MaintenanceMarginPercentage: maintMargin,
+			MarginTiers:                 parseMarginTiers(contract.Name, contract.MaintenanceMarginConfig),
+			DepthGroupings:              parseDepthGroupings(contract.DepthGrouping),
+			FundingFeeInterval:          time.Duration(contract.FundingFeeInterval) * time.Hour,
 		}
 
 		// Extract filter information
@@ -126,12 +230,36 @@ func (c *Client) fetchExchangeInfo() error {
 			case "MARKET_QTY_SIZE":
 				contractInfo.MarketMinQuantity, _ = strconv.ParseFloat(filter.MinQty, 64)
 				contractInfo.MarketMaxQuantity, _ = strconv.ParseFloat(filter.MaxQty, 64)
+			case "MIN_NOTIONAL":
+				contractInfo.MinNotional, _ = strconv.ParseFloat(filter.Notional, 64)
+			case "PRICE_FILTER", "TICK_SIZE":
+				contractInfo.TickSize, _ = strconv.ParseFloat(filter.TickSize, 64)
+			case "MAX_NUM_ORDERS":
+				contractInfo.MaxOpenOrders, _ = strconv.Atoi(filter.Limit)
 			}
 		}
-		c.ExchangeInfo[contract.Name] = contractInfo
+		exchangeInfo[contract.Name] = contractInfo
+	}
+
+	// Replace the maps rather than updating them, so readers holding the
+	// previous maps are unaffected by the refresh
+	c.infoMu.Lock()
+	previous := c.ExchangeInfo
+	c.ExchangeInfo = exchangeInfo
+	c.ConversionRates = response.ConversionRates
+	c.AssetPrecisions = response.AssetPrecisions
+	c.infoETag = exchangeInfoETag(data)
+	callbacks := slices.Clone(c.contractCallbacks)
+	c.infoMu.Unlock()
+
+	changes := diffExchangeInfo(previous, exchangeInfo)
+	for _, change := range changes {
+		for _, callback := range callbacks {
+			callback(change)
+		}
 	}
 
-	return nil
+	return changes, nil
 }
 
 // generateSignature creates an HMAC SHA256 signature for request authentication
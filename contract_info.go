@@ -0,0 +1,110 @@
+package pi42
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// maxSymbolSuggestions is the number of close matches listed when a symbol
+// is not found
+const maxSymbolSuggestions = 5
+
+// Contracts returns a copy of the loaded exchange info keyed by symbol. Use
+// it instead of reading the ExchangeInfo field while exchange info may be
+// refreshed in the background.
+func (c *Client) Contracts() map[string]ContractInfo {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+
+	return maps.Clone(c.ExchangeInfo)
+}
+
+// GetContractInfo returns the contract specification of a symbol. The
+// lookup is case-insensitive; if the symbol is unknown, the error lists
+// close matches.
+func (c *Client) GetContractInfo(symbol string) (ContractInfo, error) {
+	c.infoMu.RLock()
+	exchangeInfo := c.ExchangeInfo
+	c.infoMu.RUnlock()
+
+	if contract, ok := exchangeInfo[symbol]; ok {
+		return contract, nil
+	}
+	upper := strings.ToUpper(symbol)
+	if contract, ok := exchangeInfo[upper]; ok {
+		return contract, nil
+	}
+	for name, contract := range exchangeInfo {
+		if strings.EqualFold(name, symbol) {
+			return contract, nil
+		}
+	}
+
+	if len(exchangeInfo) == 0 {
+		return ContractInfo{}, fmt.Errorf("symbol %s not found: exchange info not loaded", symbol)
+	}
+	if matches := closeSymbolMatches(exchangeInfo, upper); len(matches) > 0 {
+		return ContractInfo{}, fmt.Errorf("symbol %s not found in exchange info (did you mean %s?)",
+			symbol, strings.Join(matches, ", "))
+	}
+	return ContractInfo{}, fmt.Errorf("symbol %s not found in exchange info", symbol)
+}
+
+// closeSymbolMatches returns the symbols closest to symbol by edit
+// distance, also counting symbols that contain it or are contained in it
+func closeSymbolMatches(exchangeInfo map[string]ContractInfo, symbol string) []string {
+	type match struct {
+		symbol   string
+		distance int
+	}
+
+	maxDistance := max(len(symbol)/3, 2)
+	var matches []match
+	for name := range exchangeInfo {
+		upper := strings.ToUpper(name)
+		distance := editDistance(symbol, upper)
+		if strings.Contains(upper, symbol) || strings.Contains(symbol, upper) {
+			distance = min(distance, 1)
+		}
+		if distance <= maxDistance {
+			matches = append(matches, match{name, distance})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.symbol, b.symbol)
+	})
+
+	var symbols []string
+	for _, m := range matches[:min(len(matches), maxSymbolSuggestions)] {
+		symbols = append(symbols, m.symbol)
+	}
+	return symbols
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
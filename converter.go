@@ -0,0 +1,84 @@
+package pi42
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Converter converts amounts between assets using the conversion rates
+// published in the exchange info response.
+//
+// Rates are keyed as <MARKET>_<KIND>_<ASSET>, e.g. INR_MARGIN_USDT = 88
+// means one USDT is worth 88 INR when used as margin in the INR market.
+type Converter struct {
+	rates      map[string]float64
+	precisions map[string]int
+}
+
+// NewConverter creates a converter from exchange conversion rates and asset precisions
+func NewConverter(conversionRates map[string]float64, assetPrecisions map[string]int) *Converter {
+	return &Converter{
+		rates:      conversionRates,
+		precisions: assetPrecisions,
+	}
+}
+
+// Converter returns a converter built from the exchange info loaded by the client
+func (c *Client) Converter() *Converter {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+
+	return NewConverter(c.ConversionRates, c.AssetPrecisions)
+}
+
+// Rate returns the margin conversion rate from one asset to another
+func (cv *Converter) Rate(from, to string) (float64, error) {
+	return cv.rate("MARGIN", from, to)
+}
+
+// SettlementRate returns the settlement conversion rate from one asset to another
+func (cv *Converter) SettlementRate(from, to string) (float64, error) {
+	return cv.rate("SETTLEMENT", from, to)
+}
+
+// rate looks up the rate of the given kind, using the inverse of the opposite
+// direction when only that one is published
+func (cv *Converter) rate(kind, from, to string) (float64, error) {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+
+	if from == to {
+		return 1, nil
+	}
+
+	if rate, ok := cv.rates[fmt.Sprintf("%s_%s_%s", to, kind, from)]; ok && rate > 0 {
+		return rate, nil
+	}
+
+	if rate, ok := cv.rates[fmt.Sprintf("%s_%s_%s", from, kind, to)]; ok && rate > 0 {
+		return 1 / rate, nil
+	}
+
+	return 0, fmt.Errorf("no %s conversion rate from %s to %s", strings.ToLower(kind), from, to)
+}
+
+// Convert converts an amount from one asset to another using the margin rate,
+// rounded to the target asset's precision
+func (cv *Converter) Convert(amount float64, from, to string) (float64, error) {
+	rate, err := cv.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return cv.Round(amount*rate, to), nil
+}
+
+// Round rounds an amount to the precision configured for the asset.
+// Amounts for assets without a known precision are returned unchanged.
+func (cv *Converter) Round(amount float64, asset string) float64 {
+	precision, ok := cv.precisions[strings.ToUpper(asset)]
+	if !ok {
+		return amount
+	}
+	return roundToDecimal(amount, precision)
+}
@@ -0,0 +1,67 @@
+package pi42
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseDepthGroupings parses the depth groupings of a contract into price
+// steps, ascending. Invalid entries are skipped.
+func parseDepthGroupings(groupings []string) []float64 {
+	var steps []float64
+	for _, grouping := range groupings {
+		step, err := strconv.ParseFloat(strings.TrimSpace(grouping), 64)
+		if err != nil || step <= 0 || slices.Contains(steps, step) {
+			continue
+		}
+		steps = append(steps, step)
+	}
+	slices.Sort(steps)
+	return steps
+}
+
+// formatDepthGrouping formats a price step as used in depth topics
+func formatDepthGrouping(step float64) string {
+	return strconv.FormatFloat(step, 'f', -1, 64)
+}
+
+// defaultGroupingFor returns the depth grouping used for a contract when
+// none is configured
+func defaultGroupingFor(contract ContractInfo) string {
+	if len(contract.DepthGroupings) == 0 {
+		return defaultDepthGrouping
+	}
+	for _, step := range contract.DepthGroupings {
+		if formatDepthGrouping(step) == defaultDepthGrouping {
+			return defaultDepthGrouping
+		}
+	}
+	return formatDepthGrouping(contract.DepthGroupings[0])
+}
+
+// SetDepthGrouping sets the price grouping of a symbol's depth stream,
+// checking that the contract supports it. Contracts without published
+// groupings accept any positive step.
+func (c *Client) SetDepthGrouping(symbol string, step float64) error {
+	contract, err := c.GetContractInfo(symbol)
+	if err != nil {
+		return err
+	}
+	if step <= 0 {
+		return fmt.Errorf("invalid depth grouping %v", step)
+	}
+
+	if len(contract.DepthGroupings) > 0 && !slices.Contains(contract.DepthGroupings, step) {
+		supported := make([]string, len(contract.DepthGroupings))
+		for i, s := range contract.DepthGroupings {
+			supported[i] = formatDepthGrouping(s)
+		}
+		return fmt.Errorf("depth grouping %v not supported for %s (supported: %s)",
+			step, contract.Symbol, strings.Join(supported, ", "))
+	}
+
+	c.WebSocket.SetDepthGrouping(contract.Symbol, formatDepthGrouping(step))
+	return nil
+}
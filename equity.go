@@ -0,0 +1,147 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultEquityInterval is how often EquityRecorder snapshots the wallet
+// when no interval is configured
+const defaultEquityInterval = 5 * time.Minute
+
+// EquitySnapshot is the futures wallet equity at a point in time
+type EquitySnapshot struct {
+	Time          time.Time
+	MarginAsset   string
+	WalletBalance float64
+	UnrealisedPnl float64
+	// Wallet balance plus unrealized PnL
+	Equity float64
+}
+
+// EquityStore persists equity snapshots
+type EquityStore interface {
+	// Append stores a snapshot
+	Append(snapshot EquitySnapshot) error
+	// Snapshots returns the snapshots taken in [from, to], oldest first. A
+	// zero time leaves that end of the range open.
+	Snapshots(from, to time.Time) ([]EquitySnapshot, error)
+}
+
+// EquityRecorder periodically snapshots the futures wallet of a margin
+// asset into an equity time series
+type EquityRecorder struct {
+	client      *Client
+	marginAsset string
+	store       EquityStore
+	interval    time.Duration
+}
+
+// NewEquityRecorder creates a recorder snapshotting the wallet of marginAsset
+// into store every interval (five minutes if zero)
+func NewEquityRecorder(client *Client, marginAsset string, store EquityStore, interval time.Duration) *EquityRecorder {
+	if interval <= 0 {
+		interval = defaultEquityInterval
+	}
+	return &EquityRecorder{
+		client:      client,
+		marginAsset: marginAsset,
+		store:       store,
+		interval:    interval,
+	}
+}
+
+// Snapshot fetches the wallet and stores a snapshot of its equity
+func (r *EquityRecorder) Snapshot() (EquitySnapshot, error) {
+	wallet, err := r.client.Wallet.FuturesWalletDetails(r.marginAsset)
+	if err != nil {
+		return EquitySnapshot{}, err
+	}
+	balances, err := wallet.Balances()
+	if err != nil {
+		return EquitySnapshot{}, err
+	}
+
+	snapshot := EquitySnapshot{
+		Time:          time.Now(),
+		MarginAsset:   balances.MarginAsset,
+		WalletBalance: balances.WalletBalance,
+		UnrealisedPnl: balances.UnrealisedPnl(),
+	}
+	if snapshot.MarginAsset == "" {
+		snapshot.MarginAsset = r.marginAsset
+	}
+	snapshot.Equity = snapshot.WalletBalance + snapshot.UnrealisedPnl
+
+	if err := r.store.Append(snapshot); err != nil {
+		return snapshot, fmt.Errorf("error storing equity snapshot: %v", err)
+	}
+	return snapshot, nil
+}
+
+// Run takes a snapshot immediately and then every interval until ctx is
+// done. Failed snapshots are logged and retried at the next interval.
+func (r *EquityRecorder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Snapshot(); err != nil {
+			logger().Warnf("Could not record equity snapshot: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// EquityStats summarizes the performance of an equity time series
+type EquityStats struct {
+	Start         EquitySnapshot
+	End           EquitySnapshot
+	Return        float64
+	ReturnPercent float64
+	// Largest fall from a peak to a later trough
+	MaxDrawdown        float64
+	MaxDrawdownPercent float64
+	PeakTime           time.Time
+	TroughTime         time.Time
+}
+
+// AnalyzeEquity computes the return and maximum drawdown of snapshots,
+// which must be ordered oldest first
+func AnalyzeEquity(snapshots []EquitySnapshot) (EquityStats, error) {
+	if len(snapshots) == 0 {
+		return EquityStats{}, fmt.Errorf("no equity snapshots to analyze")
+	}
+
+	stats := EquityStats{
+		Start: snapshots[0],
+		End:   snapshots[len(snapshots)-1],
+	}
+	stats.Return = stats.End.Equity - stats.Start.Equity
+	if stats.Start.Equity != 0 {
+		stats.ReturnPercent = stats.Return / stats.Start.Equity * 100
+	}
+
+	peak := snapshots[0]
+	for _, snapshot := range snapshots[1:] {
+		if snapshot.Equity > peak.Equity {
+			peak = snapshot
+			continue
+		}
+		if drawdown := peak.Equity - snapshot.Equity; drawdown > stats.MaxDrawdown {
+			stats.MaxDrawdown = drawdown
+			if peak.Equity > 0 {
+				stats.MaxDrawdownPercent = drawdown / peak.Equity * 100
+			}
+			stats.PeakTime = peak.Time
+			stats.TroughTime = snapshot.Time
+		}
+	}
+	return stats, nil
+}
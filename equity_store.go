@@ -0,0 +1,243 @@
+package pi42
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// inRange reports whether t lies in [from, to], treating zero bounds as open
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// MemoryEquityStore keeps equity snapshots in memory
+type MemoryEquityStore struct {
+	snapshots []EquitySnapshot
+	limit     int
+	mu        sync.RWMutex
+}
+
+// NewMemoryEquityStore creates an in-memory store keeping at most limit
+// snapshots, dropping the oldest; zero keeps all
+func NewMemoryEquityStore(limit int) *MemoryEquityStore {
+	return &MemoryEquityStore{limit: limit}
+}
+
+// Append stores a snapshot
+func (s *MemoryEquityStore) Append(snapshot EquitySnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots = append(s.snapshots, snapshot)
+	if s.limit > 0 && len(s.snapshots) > s.limit {
+		s.snapshots = append(s.snapshots[:0], s.snapshots[len(s.snapshots)-s.limit:]...)
+	}
+	return nil
+}
+
+// Snapshots returns the snapshots taken in [from, to], oldest first
+func (s *MemoryEquityStore) Snapshots(from, to time.Time) ([]EquitySnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []EquitySnapshot
+	for _, snapshot := range s.snapshots {
+		if inRange(snapshot.Time, from, to) {
+			result = append(result, snapshot)
+		}
+	}
+	return result, nil
+}
+
+// equityCSVHeader is the header row of CSVEquityStore files
+var equityCSVHeader = []string{"time", "marginAsset", "walletBalance", "unrealisedPnl", "equity"}
+
+// CSVEquityStore appends equity snapshots to a CSV file
+type CSVEquityStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewCSVEquityStore creates a store appending to the CSV file at path,
+// which is created with a header row if it does not exist
+func NewCSVEquityStore(path string) *CSVEquityStore {
+	return &CSVEquityStore{path: path}
+}
+
+// Append writes a snapshot as a row. Times are RFC 3339 in UTC.
+func (s *CSVEquityStore) Append(snapshot EquitySnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening equity file: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error opening equity file: %v", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if info.Size() == 0 {
+		if err := writer.Write(equityCSVHeader); err != nil {
+			return fmt.Errorf("error writing CSV: %v", err)
+		}
+	}
+
+	formatFloat := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	row := []string{
+		snapshot.Time.UTC().Format(time.RFC3339Nano), snapshot.MarginAsset,
+		formatFloat(snapshot.WalletBalance), formatFloat(snapshot.UnrealisedPnl), formatFloat(snapshot.Equity),
+	}
+	if err := writer.Write(row); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+	return nil
+}
+
+// Snapshots reads the snapshots taken in [from, to], oldest first. A
+// missing file has no snapshots.
+func (s *CSVEquityStore) Snapshots(from, to time.Time) ([]EquitySnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening equity file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = len(equityCSVHeader)
+
+	var result []EquitySnapshot
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %v", err)
+		}
+		if line == 1 && row[0] == equityCSVHeader[0] {
+			continue
+		}
+
+		snapshot, err := parseEquityRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV line %d: %v", line, err)
+		}
+		if inRange(snapshot.Time, from, to) {
+			result = append(result, snapshot)
+		}
+	}
+	return result, nil
+}
+
+// parseEquityRow parses a CSV row written by CSVEquityStore
+func parseEquityRow(row []string) (EquitySnapshot, error) {
+	t, err := time.Parse(time.RFC3339Nano, row[0])
+	if err != nil {
+		return EquitySnapshot{}, err
+	}
+	snapshot := EquitySnapshot{Time: t, MarginAsset: row[1]}
+	for i, field := range []*float64{&snapshot.WalletBalance, &snapshot.UnrealisedPnl, &snapshot.Equity} {
+		if *field, err = strconv.ParseFloat(row[i+2], 64); err != nil {
+			return EquitySnapshot{}, err
+		}
+	}
+	return snapshot, nil
+}
+
+// sqlIdentifier matches table names accepted by SQLEquityStore
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLEquityStore stores equity snapshots in a database/sql table. It uses
+// ? placeholders and portable column types, so it works with SQLite
+// drivers such as modernc.org/sqlite or github.com/mattn/go-sqlite3.
+type SQLEquityStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLEquityStore creates a store using table in db, creating the table
+// if it does not exist
+func NewSQLEquityStore(db *sql.DB, table string) (*SQLEquityStore, error) {
+	if !sqlIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time_ms INTEGER NOT NULL,
+	margin_asset TEXT NOT NULL,
+	wallet_balance REAL NOT NULL,
+	unrealised_pnl REAL NOT NULL,
+	equity REAL NOT NULL
+)`, table)
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("error creating equity table: %v", err)
+	}
+	return &SQLEquityStore{db: db, table: table}, nil
+}
+
+// Append inserts a snapshot. Times are stored as Unix milliseconds.
+func (s *SQLEquityStore) Append(snapshot EquitySnapshot) error {
+	query := fmt.Sprintf("INSERT INTO %s (time_ms, margin_asset, wallet_balance, unrealised_pnl, equity) VALUES (?, ?, ?, ?, ?)", s.table)
+	_, err := s.db.Exec(query, snapshot.Time.UnixMilli(), snapshot.MarginAsset,
+		snapshot.WalletBalance, snapshot.UnrealisedPnl, snapshot.Equity)
+	if err != nil {
+		return fmt.Errorf("error inserting equity snapshot: %v", err)
+	}
+	return nil
+}
+
+// Snapshots queries the snapshots taken in [from, to], oldest first
+func (s *SQLEquityStore) Snapshots(from, to time.Time) ([]EquitySnapshot, error) {
+	var fromMs, toMs int64 = 0, 1<<63 - 1
+	if !from.IsZero() {
+		fromMs = from.UnixMilli()
+	}
+	if !to.IsZero() {
+		toMs = to.UnixMilli()
+	}
+
+	query := fmt.Sprintf("SELECT time_ms, margin_asset, wallet_balance, unrealised_pnl, equity FROM %s WHERE time_ms >= ? AND time_ms <= ? ORDER BY time_ms", s.table)
+	rows, err := s.db.Query(query, fromMs, toMs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying equity snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	var result []EquitySnapshot
+	for rows.Next() {
+		var snapshot EquitySnapshot
+		var timeMs int64
+		if err := rows.Scan(&timeMs, &snapshot.MarginAsset, &snapshot.WalletBalance, &snapshot.UnrealisedPnl, &snapshot.Equity); err != nil {
+			return nil, fmt.Errorf("error reading equity snapshot: %v", err)
+		}
+		snapshot.Time = time.UnixMilli(timeMs)
+		result = append(result, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading equity snapshots: %v", err)
+	}
+	return result, nil
+}
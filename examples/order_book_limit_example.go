@@ -67,7 +67,7 @@ func orderBookLimitExample(client *pi42.Client) {
 	fmt.Printf("\n2. Placing limit buy order at %.2f (farthest bid from order book)...\n", limitPrice)
 
 	// Get contract info for precision
-	_, exists := client.ExchangeInfo[symbol]
+	_, exists := client.Contracts()[symbol]
 	if !exists {
 		log.Fatalf("Contract information for %s not found", symbol)
 	}
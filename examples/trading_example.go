@@ -103,7 +103,7 @@ func checkWalletBalance(client *pi42.Client) {
 // getContractInfo gets and displays information about a specific trading contract
 func getContractInfo(client *pi42.Client, symbol string) pi42.ContractInfo {
 	// Check if we have the information in the exchange info cache
-	contractInfo, exists := client.ExchangeInfo[symbol]
+	contractInfo, exists := client.Contracts()[symbol]
 	if !exists {
 		log.Printf("Contract information for %s not found in cache\n", symbol)
 		return pi42.ContractInfo{}
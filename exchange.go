@@ -3,11 +3,16 @@ package pi42
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 )
 
 // ExchangeAPI provides access to exchange settings endpoints
 type ExchangeAPI struct {
 	client *Client
+
+	// Position mode from the last query or update, empty if unknown
+	cachedPositionMode PositionMode
+	mu                 sync.Mutex
 }
 
 // NewExchangeAPI creates a new Exchange API instance
@@ -37,8 +42,42 @@ func (api *ExchangeAPI) ExchangeInfo(market string) (*ExchangeInfoResponse, erro
 	return &result, nil
 }
 
-// UpdatePreference updates the leverage and margin-mode for a specified contract
+// GetPreferences retrieves the leverage and margin mode currently set for a
+// contract, or for every contract if contractName is empty
+func (api *ExchangeAPI) GetPreferences(contractName string) ([]TradingPreference, error) {
+	endpoint := "/v1/exchange/preference"
+
+	params := make(map[string]string)
+	if contractName != "" {
+		params["contractName"] = contractName
+	}
+
+	data, err := api.client.Get(endpoint, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single contract may be returned as an object rather than a list
+	var result []TradingPreference
+	if err := json.Unmarshal(data, &result); err != nil {
+		var single TradingPreference
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("error parsing response: %v", err)
+		}
+		result = []TradingPreference{single}
+	}
+
+	return result, nil
+}
+
+// UpdatePreference updates the leverage and margin-mode for a specified contract.
+// The leverage is validated locally against the contract's maximum and
+// tiered caps first, returning a LeverageError if too high.
 func (api *ExchangeAPI) UpdatePreference(leverage int, marginMode, contractName string) (*PreferenceUpdateResponse, error) {
+	if err := api.validateLeverage(leverage, contractName); err != nil {
+		return nil, err
+	}
+
 	endpoint := "/v1/exchange/update/preference"
 
 	params := map[string]interface{}{
@@ -60,8 +99,13 @@ func (api *ExchangeAPI) UpdatePreference(leverage int, marginMode, contractName
 	return &result, nil
 }
 
-// UpdateLeverage updates the leverage for a specified contract
+// UpdateLeverage updates the leverage for a specified contract. The leverage
+// is validated locally as in UpdatePreference.
 func (api *ExchangeAPI) UpdateLeverage(leverage int, contractName string) (*LeverageUpdateResponse, error) {
+	if err := api.validateLeverage(leverage, contractName); err != nil {
+		return nil, err
+	}
+
 	endpoint := "/v1/exchange/update/leverage"
 
 	params := map[string]interface{}{
@@ -0,0 +1,124 @@
+package pi42
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// exchangeInfoCacheFile is the on-disk format of the exchange info cache
+type exchangeInfoCacheFile struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Content hash of the response, used like an ETag to detect changes
+	ETag     string          `json:"etag"`
+	Response json.RawMessage `json:"response"`
+}
+
+// NewClientWithCache creates a client that persists exchange info to
+// cachePath. If the cache file exists, the client starts with the cached
+// exchange info and fetches fresh data in the background, so restarts
+// don't block on the network or run with an empty ExchangeInfo map;
+// changes found by that fetch are reported to OnContractChange callbacks.
+// Without a usable cache the exchange info is fetched as in NewClient.
+//
+// Because the background fetch replaces them, the ExchangeInfo,
+// ConversionRates and AssetPrecisions fields must not be read while it runs;
+// use Contracts, GetContractInfo and Converter instead.
+func NewClientWithCache(apiKey, apiSecret, cachePath string) *Client {
+	client := newClient(apiKey, apiSecret)
+	client.infoCachePath = cachePath
+
+	if err := client.readExchangeInfoCache(); err != nil {
+		if !os.IsNotExist(err) {
+			logger().Warnf("Could not read exchange info cache: %v", err)
+		}
+		client.loadExchangeInfo()
+		return client
+	}
+
+	logger().Infof("Exchange info loaded from cache, refreshing in the background")
+	go client.loadExchangeInfo()
+	return client
+}
+
+// ExchangeInfoFetchedAt returns when the loaded exchange info was fetched
+// from the exchange, which may predate the client if it came from the cache
+func (c *Client) ExchangeInfoFetchedAt() time.Time {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+
+	return c.infoFetchedAt
+}
+
+// readExchangeInfoCache loads exchange info from the cache file
+func (c *Client) readExchangeInfoCache() error {
+	data, err := os.ReadFile(c.infoCachePath)
+	if err != nil {
+		return err
+	}
+
+	var cache exchangeInfoCacheFile
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return fmt.Errorf("error parsing exchange info cache: %v", err)
+	}
+	if _, err := c.applyExchangeInfo(cache.Response); err != nil {
+		return err
+	}
+
+	c.infoMu.Lock()
+	c.infoETag = cache.ETag
+	c.infoFetchedAt = cache.FetchedAt
+	c.infoMu.Unlock()
+	return nil
+}
+
+// saveExchangeInfoCache writes a fetched exchange info response to the
+// cache file, if one is configured. Failures are logged.
+func (c *Client) saveExchangeInfoCache(response []byte) {
+	c.infoMu.RLock()
+	path := c.infoCachePath
+	fetchedAt := c.infoFetchedAt
+	c.infoMu.RUnlock()
+	if path == "" {
+		return
+	}
+
+	data, err := json.Marshal(exchangeInfoCacheFile{
+		FetchedAt: fetchedAt,
+		ETag:      exchangeInfoETag(response),
+		Response:  response,
+	})
+	if err != nil {
+		logger().Warnf("Could not encode exchange info cache: %v", err)
+		return
+	}
+
+	// Write to a temporary file and rename it, so a crash never leaves a
+	// truncated cache behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		logger().Warnf("Could not write exchange info cache: %v", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		logger().Warnf("Could not write exchange info cache: %v", err)
+	}
+}
+
+// exchangeInfoETag returns the content hash of an exchange info response
+func exchangeInfoETag(response []byte) string {
+	sum := sha256.Sum256(response)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,125 @@
+package pi42
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ContractChangeKind is the kind of change of a contract between two
+// exchange info loads
+type ContractChangeKind string
+
+const (
+	ContractAdded   ContractChangeKind = "ADDED"
+	ContractRemoved ContractChangeKind = "REMOVED"
+	ContractUpdated ContractChangeKind = "UPDATED"
+)
+
+// ContractChange describes a contract added, removed or updated by an
+// exchange info refresh
+type ContractChange struct {
+	Kind   ContractChangeKind
+	Symbol string
+	// Contract before and after the refresh; zero when added or removed
+	Previous ContractInfo
+	Current  ContractInfo
+	// Names of the ContractInfo fields that changed, e.g. "MaxLeverage" or
+	// "MinQuantity"; only set for updates
+	Fields []string
+}
+
+// OnContractChange registers a callback invoked for each contract added,
+// removed or updated when the exchange info is reloaded
+func (c *Client) OnContractChange(callback func(ContractChange)) {
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+
+	c.contractCallbacks = append(c.contractCallbacks, callback)
+}
+
+// RefreshExchangeInfo reloads the exchange info and returns how the
+// contracts changed, notifying OnContractChange callbacks
+func (c *Client) RefreshExchangeInfo() ([]ContractChange, error) {
+	return c.fetchExchangeInfo()
+}
+
+// AutoRefreshExchangeInfo reloads the exchange info every interval until
+// ctx is done, so long-running clients pick up new listings and filter or
+// leverage changes. Failed refreshes are logged and retried at the next
+// interval. It returns immediately if interval is not positive.
+//
+// Refreshing replaces the ExchangeInfo, ConversionRates and AssetPrecisions
+// fields, so they must not be read while it runs; use Contracts,
+// GetContractInfo and Converter instead.
+func (c *Client) AutoRefreshExchangeInfo(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		logger().Warnf("Not refreshing exchange info: invalid interval %v", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := c.RefreshExchangeInfo(); err != nil {
+				logger().Warnf("Could not refresh exchange info: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// diffExchangeInfo compares two exchange info loads, returning changes
+// sorted by symbol. The initial load, with no previous contracts, reports
+// no changes.
+func diffExchangeInfo(previous, current map[string]ContractInfo) []ContractChange {
+	if len(previous) == 0 {
+		return nil
+	}
+
+	var changes []ContractChange
+
+	for symbol, contract := range current {
+		old, ok := previous[symbol]
+		if !ok {
+			changes = append(changes, ContractChange{Kind: ContractAdded, Symbol: symbol, Current: contract})
+			continue
+		}
+		if fields := changedContractFields(old, contract); len(fields) > 0 {
+			changes = append(changes, ContractChange{
+				Kind:     ContractUpdated,
+				Symbol:   symbol,
+				Previous: old,
+				Current:  contract,
+				Fields:   fields,
+			})
+		}
+	}
+	for symbol, contract := range previous {
+		if _, ok := current[symbol]; !ok {
+			changes = append(changes, ContractChange{Kind: ContractRemoved, Symbol: symbol, Previous: contract})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b ContractChange) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	return changes
+}
+
+// changedContractFields returns the names of the fields that differ
+func changedContractFields(a, b ContractInfo) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var fields []string
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			fields = append(fields, va.Type().Field(i).Name)
+		}
+	}
+	return fields
+}
@@ -48,6 +48,7 @@ type Filter struct {
 	MaxQty     string `json:"maxQty,omitempty"`
 	Limit      string `json:"limit,omitempty"`
 	Notional   string `json:"notional,omitempty"`
+	TickSize   string `json:"tickSize,omitempty"`
 }
 
 // PreferenceUpdateResponse represents the response from updating trading preferences
@@ -62,3 +63,10 @@ type LeverageUpdateResponse struct {
 	UpdatedLeverage int    `json:"updatedLeverage"`
 	ContractName    string `json:"contractName"`
 }
+
+// TradingPreference represents the leverage and margin mode set for a contract
+type TradingPreference struct {
+	ContractName string     `json:"contractName"`
+	MarginMode   MarginMode `json:"marginMode"`
+	Leverage     int        `json:"leverage"`
+}
@@ -0,0 +1,43 @@
+package pi42
+
+import (
+	"fmt"
+	"time"
+)
+
+// NextFundingTime returns the first funding settlement strictly after t.
+// Settlements are assumed to be aligned to midnight UTC at multiples of the
+// funding interval. It reports false if the interval is unknown.
+func (ci ContractInfo) NextFundingTime(t time.Time) (time.Time, bool) {
+	interval := ci.FundingFeeInterval
+	if interval <= 0 {
+		return time.Time{}, false
+	}
+
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	elapsed := t.Sub(midnight)
+	return midnight.Add((elapsed/interval + 1) * interval), true
+}
+
+// NextFundingTime returns the upcoming funding settlement of a symbol. The
+// markPriceUpdate stream reports the exchange's exact next funding time as
+// MarkPriceEvent.NextFundingTime.
+func (c *Client) NextFundingTime(symbol string) (time.Time, error) {
+	contract, err := c.GetContractInfo(symbol)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, ok := contract.NextFundingTime(time.Now())
+	if !ok {
+		return time.Time{}, fmt.Errorf("funding interval of %s is unknown", contract.Symbol)
+	}
+	return next, nil
+}
+
+// CrossesFunding reports whether a funding settlement of the contract falls
+// within (from, to], e.g. to avoid holding a position across funding
+func (ci ContractInfo) CrossesFunding(from, to time.Time) bool {
+	next, ok := ci.NextFundingTime(from)
+	return ok && !next.After(to)
+}
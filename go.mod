@@ -5,6 +5,7 @@ go 1.24.1
 require github.com/joho/godotenv v1.5.1
 
 require (
+	github.com/zishang520/engine.io-client-go v1.0.1
 	github.com/zishang520/engine.io/v2 v2.4.13
 	golang.org/x/net v0.38.0
 )
@@ -20,7 +21,6 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
-	github.com/zishang520/engine.io-client-go v1.0.1 // indirect
 	github.com/zishang520/socket.io-go-parser/v2 v2.4.6 // indirect
 	github.com/zishang520/socket.io/v2 v2.4.11 // indirect
 	resty.dev/v3 v3.0.0-beta.2 // indirect
@@ -31,7 +31,7 @@ require (
 	github.com/gofiber/fiber/v2 v2.52.8
 	github.com/google/pprof v0.0.0-20230821062121-407c9e7a662f // indirect
 	github.com/gookit/color v1.5.4 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/gorilla/websocket v1.5.3
 	github.com/onsi/ginkgo/v2 v2.12.0 // indirect
 	github.com/quic-go/qpack v0.5.1 // indirect
 	github.com/quic-go/quic-go v0.51.0 // indirect
@@ -44,7 +44,7 @@ require (
 	go.uber.org/mock v0.5.1 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/mod v0.18.0 // indirect
-	golang.org/x/sync v0.12.0 // indirect
+	golang.org/x/sync v0.12.0
 	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/tools v0.22.0 // indirect
@@ -0,0 +1,148 @@
+// Package indicators provides common technical indicators that operate on
+// pi42 kline data, both over complete series and on streaming updates.
+//
+// Series functions return slices aligned with their input; positions before
+// an indicator has enough data are set to NaN.
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/revanthstrakz/pi42"
+)
+
+// Closes extracts the close prices from a kline series
+func Closes(klines []pi42.KlineData) ([]float64, error) {
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		c, err := strconv.ParseFloat(k.Close, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse close of kline %d: %v", i, err)
+		}
+		closes[i] = c
+	}
+	return closes, nil
+}
+
+// nanSlice returns a slice of length n filled with NaN
+func nanSlice(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
+
+// SMA calculates the simple moving average over period values
+func SMA(values []float64, period int) []float64 {
+	result := nanSlice(len(values))
+	if period < 1 {
+		return result
+	}
+
+	sma := NewStreamingSMA(period)
+	for i, v := range values {
+		if avg, ok := sma.Update(v); ok {
+			result[i] = avg
+		}
+	}
+	return result
+}
+
+// EMA calculates the exponential moving average, seeded with the SMA of the
+// first period values
+func EMA(values []float64, period int) []float64 {
+	result := nanSlice(len(values))
+	if period < 1 {
+		return result
+	}
+
+	ema := NewStreamingEMA(period)
+	for i, v := range values {
+		if avg, ok := ema.Update(v); ok {
+			result[i] = avg
+		}
+	}
+	return result
+}
+
+// RSI calculates the relative strength index using Wilder's smoothing
+func RSI(values []float64, period int) []float64 {
+	result := nanSlice(len(values))
+	if period < 1 {
+		return result
+	}
+
+	rsi := NewStreamingRSI(period)
+	for i, v := range values {
+		if r, ok := rsi.Update(v); ok {
+			result[i] = r
+		}
+	}
+	return result
+}
+
+// MACD calculates the MACD line, its signal line and the histogram
+func MACD(values []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	macd = nanSlice(len(values))
+	signalLine = nanSlice(len(values))
+	histogram = nanSlice(len(values))
+	if fast < 1 || slow < 1 || signal < 1 {
+		return
+	}
+
+	m := NewStreamingMACD(fast, slow, signal)
+	for i, v := range values {
+		value, ok := m.Update(v)
+		if !ok {
+			continue
+		}
+		macd[i] = value.MACD
+		if value.SignalReady {
+			signalLine[i] = value.Signal
+			histogram[i] = value.Histogram
+		}
+	}
+	return
+}
+
+// BollingerBands calculates the middle band (SMA) and the upper and lower
+// bands at k standard deviations
+func BollingerBands(values []float64, period int, k float64) (upper, middle, lower []float64) {
+	upper = nanSlice(len(values))
+	middle = nanSlice(len(values))
+	lower = nanSlice(len(values))
+	if period < 1 {
+		return
+	}
+
+	bb := NewStreamingBollinger(period, k)
+	for i, v := range values {
+		if bands, ok := bb.Update(v); ok {
+			upper[i] = bands.Upper
+			middle[i] = bands.Middle
+			lower[i] = bands.Lower
+		}
+	}
+	return
+}
+
+// VWAP calculates the cumulative volume-weighted average price of a kline
+// series using each candle's typical price
+func VWAP(klines []pi42.KlineData) ([]float64, error) {
+	result := nanSlice(len(klines))
+
+	vwap := NewStreamingVWAP()
+	for i, k := range klines {
+		value, err := vwap.Update(k)
+		if err != nil {
+			return nil, fmt.Errorf("kline %d: %v", i, err)
+		}
+		if vwap.Ready() {
+			result[i] = value
+		}
+	}
+	return result, nil
+}
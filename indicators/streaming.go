@@ -0,0 +1,266 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/revanthstrakz/pi42"
+)
+
+// StreamingSMA calculates a simple moving average one value at a time
+type StreamingSMA struct {
+	period int
+	window []float64
+	sum    float64
+}
+
+// NewStreamingSMA creates a streaming SMA over period values. Like SMA, a
+// period below 1 never produces a value.
+func NewStreamingSMA(period int) *StreamingSMA {
+	return &StreamingSMA{period: period}
+}
+
+// Update adds a value and returns the current average once period values were seen
+func (s *StreamingSMA) Update(value float64) (float64, bool) {
+	if s.period < 1 {
+		return 0, false
+	}
+	s.window = append(s.window, value)
+	s.sum += value
+	if len(s.window) > s.period {
+		s.sum -= s.window[0]
+		s.window = s.window[1:]
+	}
+	if len(s.window) < s.period {
+		return 0, false
+	}
+	return s.sum / float64(s.period), true
+}
+
+// StreamingEMA calculates an exponential moving average one value at a time
+type StreamingEMA struct {
+	alpha float64
+	seed  *StreamingSMA
+	value float64
+	ready bool
+}
+
+// NewStreamingEMA creates a streaming EMA over period values. Like EMA, a
+// period below 1 never produces a value.
+func NewStreamingEMA(period int) *StreamingEMA {
+	return &StreamingEMA{
+		alpha: 2 / float64(period+1),
+		seed:  NewStreamingSMA(period),
+	}
+}
+
+// Update adds a value and returns the current average once period values were seen
+func (e *StreamingEMA) Update(value float64) (float64, bool) {
+	if !e.ready {
+		avg, ok := e.seed.Update(value)
+		if !ok {
+			return 0, false
+		}
+		e.value = avg
+		e.ready = true
+		return e.value, true
+	}
+
+	e.value = (value-e.value)*e.alpha + e.value
+	return e.value, true
+}
+
+// StreamingRSI calculates the relative strength index one value at a time
+type StreamingRSI struct {
+	period  int
+	prev    float64
+	count   int
+	avgGain float64
+	avgLoss float64
+}
+
+// NewStreamingRSI creates a streaming RSI over period changes. Like RSI, a
+// period below 1 never produces a value.
+func NewStreamingRSI(period int) *StreamingRSI {
+	return &StreamingRSI{period: period}
+}
+
+// Update adds a value and returns the current RSI once period changes were seen
+func (r *StreamingRSI) Update(value float64) (float64, bool) {
+	if r.period < 1 {
+		return 0, false
+	}
+	r.count++
+	if r.count == 1 {
+		r.prev = value
+		return 0, false
+	}
+
+	change := value - r.prev
+	r.prev = value
+	gain := math.Max(change, 0)
+	loss := math.Max(-change, 0)
+
+	changes := r.count - 1
+	if changes <= r.period {
+		// Accumulate the initial simple average
+		r.avgGain += gain / float64(r.period)
+		r.avgLoss += loss / float64(r.period)
+		if changes < r.period {
+			return 0, false
+		}
+	} else {
+		r.avgGain = (r.avgGain*float64(r.period-1) + gain) / float64(r.period)
+		r.avgLoss = (r.avgLoss*float64(r.period-1) + loss) / float64(r.period)
+	}
+
+	if r.avgLoss == 0 {
+		return 100, true
+	}
+	rs := r.avgGain / r.avgLoss
+	return 100 - 100/(1+rs), true
+}
+
+// MACDValue holds a single MACD calculation result
+type MACDValue struct {
+	MACD        float64
+	Signal      float64
+	Histogram   float64
+	SignalReady bool // Whether Signal and Histogram are populated
+}
+
+// StreamingMACD calculates MACD one value at a time
+type StreamingMACD struct {
+	fast   *StreamingEMA
+	slow   *StreamingEMA
+	signal *StreamingEMA
+}
+
+// NewStreamingMACD creates a streaming MACD with the given EMA periods. Like
+// MACD, any period below 1 means no value is ever produced.
+func NewStreamingMACD(fast, slow, signal int) *StreamingMACD {
+	return &StreamingMACD{
+		fast:   NewStreamingEMA(fast),
+		slow:   NewStreamingEMA(slow),
+		signal: NewStreamingEMA(signal),
+	}
+}
+
+// Update adds a value and returns the MACD once both EMAs are ready
+func (m *StreamingMACD) Update(value float64) (MACDValue, bool) {
+	fast, fastOK := m.fast.Update(value)
+	slow, slowOK := m.slow.Update(value)
+	if !fastOK || !slowOK {
+		return MACDValue{}, false
+	}
+
+	result := MACDValue{MACD: fast - slow}
+	if signal, ok := m.signal.Update(result.MACD); ok {
+		result.Signal = signal
+		result.Histogram = result.MACD - signal
+		result.SignalReady = true
+	}
+	return result, true
+}
+
+// Bands holds a single Bollinger Bands calculation result
+type Bands struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// StreamingBollinger calculates Bollinger Bands one value at a time
+type StreamingBollinger struct {
+	period int
+	k      float64
+	window []float64
+}
+
+// NewStreamingBollinger creates streaming Bollinger Bands over period values
+// at k standard deviations. Like BollingerBands, a period below 1 never
+// produces a value.
+func NewStreamingBollinger(period int, k float64) *StreamingBollinger {
+	return &StreamingBollinger{period: period, k: k}
+}
+
+// Update adds a value and returns the bands once period values were seen
+func (b *StreamingBollinger) Update(value float64) (Bands, bool) {
+	if b.period < 1 {
+		return Bands{}, false
+	}
+	b.window = append(b.window, value)
+	if len(b.window) > b.period {
+		b.window = b.window[1:]
+	}
+	if len(b.window) < b.period {
+		return Bands{}, false
+	}
+
+	sum := 0.0
+	for _, v := range b.window {
+		sum += v
+	}
+	middle := sum / float64(b.period)
+
+	variance := 0.0
+	for _, v := range b.window {
+		variance += (v - middle) * (v - middle)
+	}
+	deviation := math.Sqrt(variance / float64(b.period))
+
+	return Bands{
+		Upper:  middle + b.k*deviation,
+		Middle: middle,
+		Lower:  middle - b.k*deviation,
+	}, true
+}
+
+// StreamingVWAP calculates a cumulative volume-weighted average price
+type StreamingVWAP struct {
+	priceVolume float64
+	volume      float64
+}
+
+// NewStreamingVWAP creates a streaming VWAP
+func NewStreamingVWAP() *StreamingVWAP {
+	return &StreamingVWAP{}
+}
+
+// Update adds a kline and returns the current VWAP
+func (v *StreamingVWAP) Update(kline pi42.KlineData) (float64, error) {
+	values := make([]float64, 4)
+	for i, s := range []string{kline.High, kline.Low, kline.Close, kline.Volume} {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse kline value: %v", err)
+		}
+		values[i] = f
+	}
+
+	typical := (values[0] + values[1] + values[2]) / 3
+	v.priceVolume += typical * values[3]
+	v.volume += values[3]
+
+	return v.Value(), nil
+}
+
+// Ready reports whether any volume has been seen
+func (v *StreamingVWAP) Ready() bool {
+	return v.volume > 0
+}
+
+// Value returns the current VWAP, or 0 when no volume has been seen
+func (v *StreamingVWAP) Value() float64 {
+	if v.volume == 0 {
+		return 0
+	}
+	return v.priceVolume / v.volume
+}
+
+// Reset clears the accumulated values, e.g. at the start of a new session
+func (v *StreamingVWAP) Reset() {
+	v.priceVolume = 0
+	v.volume = 0
+}
@@ -0,0 +1,127 @@
+package pi42
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// KlineGap describes a range of missing candles in a kline series
+type KlineGap struct {
+	StartTime int64 // Start time of the first missing candle in milliseconds
+	EndTime   int64 // Start time of the last missing candle in milliseconds
+	Missing   int   // Number of missing candles
+	Repaired  int   // Number of candles recovered from the API
+}
+
+// KlineRepairReport summarizes the problems found in a kline series
+type KlineRepairReport struct {
+	Duplicates int        // Number of duplicate candles removed
+	Gaps       []KlineGap // Missing ranges and how many candles were recovered
+}
+
+// Unrepaired returns the number of candles that are still missing
+func (r KlineRepairReport) Unrepaired() int {
+	count := 0
+	for _, gap := range r.Gaps {
+		count += gap.Missing - gap.Repaired
+	}
+	return count
+}
+
+// FindKlineGaps sorts a kline series, removes duplicate candles and returns
+// the cleaned series along with a report of missing intervals
+func FindKlineGaps(klines []KlineData, interval KlineInterval) ([]KlineData, KlineRepairReport, error) {
+	var report KlineRepairReport
+
+	if err := interval.Validate(); err != nil {
+		return nil, report, err
+	}
+	stepMs := interval.Duration().Milliseconds()
+
+	type entry struct {
+		start int64
+		kline KlineData
+	}
+	entries := make([]entry, 0, len(klines))
+	for i, k := range klines {
+		start, err := strconv.ParseInt(k.StartTime, 10, 64)
+		if err != nil {
+			return nil, report, fmt.Errorf("could not parse start time of kline %d: %v", i, err)
+		}
+		entries = append(entries, entry{start: start, kline: k})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].start < entries[j].start })
+
+	cleaned := make([]KlineData, 0, len(entries))
+	for i, e := range entries {
+		if i > 0 {
+			prev := entries[i-1].start
+			if e.start == prev {
+				report.Duplicates++
+				continue
+			}
+			if missing := int((e.start - prev) / stepMs); missing > 1 {
+				report.Gaps = append(report.Gaps, KlineGap{
+					StartTime: prev + stepMs,
+					EndTime:   e.start - stepMs,
+					Missing:   missing - 1,
+				})
+			}
+		}
+		cleaned = append(cleaned, e.kline)
+	}
+
+	return cleaned, report, nil
+}
+
+// RepairKlines removes duplicates from a kline series and re-requests any
+// missing ranges from the API. It returns the repaired series in time order
+// and a report of what was fixed; gaps the exchange cannot fill remain
+// listed with fewer repaired than missing candles.
+func (api *MarketAPI) RepairKlines(pair string, interval KlineInterval, klines []KlineData) ([]KlineData, KlineRepairReport, error) {
+	cleaned, report, err := FindKlineGaps(klines, interval)
+	if err != nil {
+		return nil, report, err
+	}
+	if len(report.Gaps) == 0 {
+		return cleaned, report, nil
+	}
+
+	repaired := cleaned
+	for _, gap := range report.Gaps {
+		missing, err := api.GetKlines(KlinesParams{
+			Pair:      pair,
+			Interval:  interval,
+			StartTime: gap.StartTime,
+			EndTime:   gap.EndTime,
+			Limit:     gap.Missing,
+		})
+		if err != nil {
+			return nil, report, fmt.Errorf("failed to fetch klines for gap starting at %d: %v", gap.StartTime, err)
+		}
+		repaired = append(repaired, missing...)
+	}
+
+	// Re-sort, drop candles the API returned twice, and count what is still missing
+	repaired, remaining, err := FindKlineGaps(repaired, interval)
+	if err != nil {
+		return nil, report, err
+	}
+	for i := range report.Gaps {
+		report.Gaps[i].Repaired = report.Gaps[i].Missing - missingWithin(remaining.Gaps, report.Gaps[i])
+	}
+
+	return repaired, report, nil
+}
+
+// missingWithin counts the candles of gaps that fall inside the given range
+func missingWithin(gaps []KlineGap, within KlineGap) int {
+	count := 0
+	for _, gap := range gaps {
+		if gap.StartTime >= within.StartTime && gap.EndTime <= within.EndTime {
+			count += gap.Missing
+		}
+	}
+	return count
+}
@@ -0,0 +1,113 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamKlines returns a channel that first delivers the last lookback candles
+// from the REST API and then continues with live closed candles from the
+// WebSocket. Candles missed while the connection was down are backfilled from
+// REST before the next live candle, and duplicates are skipped, so consumers
+// see a continuous series. The channel is closed when ctx is cancelled.
+func (api *MarketAPI) StreamKlines(ctx context.Context, pair string, interval KlineInterval, lookback int) (<-chan KlineData, error) {
+	if err := interval.Validate(); err != nil {
+		return nil, err
+	}
+	step := interval.Duration()
+
+	history, err := api.GetKlines(KlinesParams{
+		Pair:     pair,
+		Interval: interval,
+		Limit:    lookback,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kline history: %v", err)
+	}
+
+	sc := NewSocketClient()
+	sc.AddStream(KlineTopic(pair, interval), "kline")
+	events, _ := sc.GetEventChannel("kline")
+	go func() {
+		if err := sc.Connect(ctx); err != nil {
+			logger().Warnf("Kline stream for %s not connected: %v", pair, err)
+		}
+	}()
+
+	out := make(chan KlineData, len(history)+1)
+	go func() {
+		defer close(out)
+
+		var lastStart int64
+		emit := func(kline KlineData) bool {
+			start, err := strconv.ParseInt(kline.StartTime, 10, 64)
+			if err != nil || start <= lastStart {
+				return true // Unparseable or already delivered
+			}
+			select {
+			case out <- kline:
+				lastStart = start
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// Seed with history; the newest REST candle may still be open
+		now := time.Now().UnixMilli()
+		for _, kline := range history {
+			if end, err := strconv.ParseInt(kline.EndTime, 10, 64); err == nil && end >= now {
+				continue
+			}
+			if !emit(kline) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, open := <-events:
+				if !open {
+					return
+				}
+				payload, ok := event.Payload.(*KlineEvent)
+				if !ok {
+					logger().Warnf("Could not decode kline event: %v", event.Data)
+					continue
+				}
+				if !payload.Kline.IsClosed || !strings.EqualFold(payload.Symbol, pair) {
+					continue
+				}
+
+				// Backfill any candles missed since the last delivered one
+				if lastStart > 0 && payload.Kline.StartTime > lastStart+step.Milliseconds() {
+					missing, err := api.GetKlines(KlinesParams{
+						Pair:      pair,
+						Interval:  interval,
+						StartTime: lastStart + step.Milliseconds(),
+						EndTime:   payload.Kline.StartTime - 1,
+					})
+					if err != nil {
+						logger().Warnf("Error backfilling klines for %s: %v", pair, err)
+					}
+					for _, kline := range missing {
+						if !emit(kline) {
+							return
+						}
+					}
+				}
+
+				if !emit(payload.KlineData()) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package pi42
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LedgerEntryType is the kind of balance change recorded in the ledger.
+// Values are the transaction types reported by the exchange; types not
+// listed here are passed through unchanged.
+type LedgerEntryType string
+
+// Known ledger entry types
+const (
+	LedgerFundingFee  LedgerEntryType = "FUNDING_FEE"
+	LedgerCommission  LedgerEntryType = "COMMISSION"
+	LedgerRealizedPnl LedgerEntryType = "REALIZED_PNL"
+	LedgerTransfer    LedgerEntryType = "TRANSFER"
+)
+
+// LedgerEntry is a single balance change of the account. Amount is signed:
+// negative for fees paid and losses.
+type LedgerEntry struct {
+	ID     int
+	Time   time.Time
+	Type   LedgerEntryType
+	Amount float64
+	Asset  string
+	Symbol string
+}
+
+// LedgerFilter selects ledger entries
+type LedgerFilter struct {
+	// Entry types to return; empty returns every type
+	Types          []LedgerEntryType
+	StartTimestamp int64  // Milliseconds; optional
+	EndTimestamp   int64  // Milliseconds; optional
+	Symbol         string // Optional contract pair
+	PositionID     string // Optional
+	PageSize       int    // Optional
+}
+
+// GetLedger returns the account's balance changes (funding fees,
+// commissions, realized PnL and transfers) from the transaction history,
+// filtered by type and time range
+func (api *UserDataAPI) GetLedger(filter LedgerFilter) ([]LedgerEntry, error) {
+	transactions, err := api.GetTransactionHistory(TransactionHistoryParams{
+		DataQueryParams: DataQueryParams{
+			StartTimestamp: filter.StartTimestamp,
+			EndTimestamp:   filter.EndTimestamp,
+			PageSize:       filter.PageSize,
+			Symbol:         filter.Symbol,
+		},
+		PositionID: filter.PositionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LedgerEntry, 0, len(transactions))
+	for _, tx := range transactions {
+		entry, err := newLedgerEntry(tx)
+		if err != nil {
+			return nil, err
+		}
+		if len(filter.Types) > 0 && !slices.Contains(filter.Types, entry.Type) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// newLedgerEntry converts a transaction history record to a ledger entry
+func newLedgerEntry(tx TransactionHistoryItem) (LedgerEntry, error) {
+	t, err := tx.ParsedTime()
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("error parsing time of transaction %d: %v", tx.ID, err)
+	}
+	return LedgerEntry{
+		ID:     tx.ID,
+		Time:   t,
+		Type:   LedgerEntryType(strings.ToUpper(tx.Type)),
+		Amount: tx.Amount,
+		Asset:  tx.Asset,
+		Symbol: tx.Symbol,
+	}, nil
+}
+
+// LedgerTotals sums entry amounts per type and asset, e.g. the funding fees
+// paid in INR: totals[LedgerFundingFee]["INR"]
+func LedgerTotals(entries []LedgerEntry) map[LedgerEntryType]map[string]float64 {
+	totals := make(map[LedgerEntryType]map[string]float64)
+	for _, entry := range entries {
+		if totals[entry.Type] == nil {
+			totals[entry.Type] = make(map[string]float64)
+		}
+		totals[entry.Type][entry.Asset] += entry.Amount
+	}
+	return totals
+}
@@ -0,0 +1,80 @@
+package pi42
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeverageError is returned when a requested leverage exceeds what the
+// contract allows
+type LeverageError struct {
+	Symbol    string
+	Requested int
+	Max       float64
+	// Notional of the open position whose margin tier caps the leverage;
+	// zero when the contract-wide maximum applies
+	Notional float64
+}
+
+// Error implements the error interface
+func (e LeverageError) Error() string {
+	if e.Notional > 0 {
+		return fmt.Sprintf("leverage %dx exceeds the %vx allowed for a %s position of notional %v",
+			e.Requested, e.Max, e.Symbol, e.Notional)
+	}
+	return fmt.Sprintf("leverage %dx exceeds the maximum of %vx for %s", e.Requested, e.Max, e.Symbol)
+}
+
+// MaxLeverageFor returns the maximum leverage for a position of the given
+// notional value, the lower of the contract's maximum and the cap of the
+// notional's margin tier. Zero means unknown.
+func (ci ContractInfo) MaxLeverageFor(notional float64) float64 {
+	maxLeverage := ci.MaxLeverage
+	if tier, ok := ci.LookupTier(notional); ok && tier.MaxLeverage > 0 {
+		if maxLeverage <= 0 || tier.MaxLeverage < maxLeverage {
+			maxLeverage = tier.MaxLeverage
+		}
+	}
+	return maxLeverage
+}
+
+// validateLeverage checks a leverage against the contract's maximum and,
+// if the contract has tiered caps, against the cap for the size of the
+// open position. Contracts missing from the exchange info are not checked.
+func (api *ExchangeAPI) validateLeverage(leverage int, contractName string) error {
+	if leverage < 1 {
+		return fmt.Errorf("invalid leverage %d: must be at least 1", leverage)
+	}
+
+	contract, err := api.client.GetContractInfo(contractName)
+	if err != nil {
+		return nil
+	}
+	if contract.MaxLeverage > 0 && float64(leverage) > contract.MaxLeverage {
+		return LeverageError{Symbol: contract.Symbol, Requested: leverage, Max: contract.MaxLeverage}
+	}
+
+	hasTierCaps := false
+	for _, tier := range contract.MarginTiers {
+		hasTierCaps = hasTierCaps || tier.MaxLeverage > 0
+	}
+	if !hasTierCaps {
+		return nil
+	}
+
+	positions, err := api.client.Position.GetPositions(PositionStatusOpen, PositionQueryParams{Symbol: contract.Symbol})
+	if err != nil {
+		logger().Debugf("Could not check tiered leverage cap of %s: %v", contract.Symbol, err)
+		return nil
+	}
+	for _, position := range positions {
+		if !strings.EqualFold(position.ContractPair, contract.Symbol) {
+			continue
+		}
+		notional := position.Notional(position.EntryPrice)
+		if maxLeverage := contract.MaxLeverageFor(notional); maxLeverage > 0 && float64(leverage) > maxLeverage {
+			return LeverageError{Symbol: contract.Symbol, Requested: leverage, Max: maxLeverage, Notional: notional}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ListenKeyManager owns the lifecycle of a user data stream listen key: it
+// creates the key, refreshes it on a timer and recreates it when it expires,
+// notifying listeners so they can reconnect with the new key
+type ListenKeyManager struct {
+	client *Client
+
+	// Current listen key, empty until Create is called
+	key string
+	// How often the key is refreshed
+	interval time.Duration
+	// Callbacks invoked with a newly created key after a renewal
+	onRenew []func(listenKey string)
+	// Mutex for thread-safe access to the key and callbacks
+	mu sync.Mutex
+}
+
+// NewListenKeyManager creates a listen key manager for the client
+func NewListenKeyManager(client *Client) *ListenKeyManager {
+	return &ListenKeyManager{
+		client:   client,
+		interval: listenKeyKeepAliveInterval,
+	}
+}
+
+// SetKeepAliveInterval changes how often the key is refreshed. Keys expire
+// after 60 minutes without a refresh. A non-positive interval restores the
+// default of 10 minutes.
+func (m *ListenKeyManager) SetKeepAliveInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = listenKeyKeepAliveInterval
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.interval = interval
+}
+
+// OnRenew registers a callback invoked with the new key whenever the key is
+// recreated, e.g. to reconnect a stream
+func (m *ListenKeyManager) OnRenew(callback func(listenKey string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.onRenew = append(m.onRenew, callback)
+}
+
+// Key returns the current listen key
+func (m *ListenKeyManager) Key() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.key
+}
+
+// Create creates a new listen key and makes it the current key
+func (m *ListenKeyManager) Create() (string, error) {
+	response, err := m.client.UserData.CreateListenKey()
+	if err != nil {
+		return "", fmt.Errorf("error creating listen key: %v", err)
+	}
+	listenKey, ok := response["listenKey"]
+	if !ok || listenKey == "" {
+		return "", fmt.Errorf("listen key not found in response: %v", response)
+	}
+
+	m.mu.Lock()
+	m.key = listenKey
+	m.mu.Unlock()
+
+	return listenKey, nil
+}
+
+// Renew creates a new listen key after the current one expired and notifies
+// the OnRenew callbacks
+func (m *ListenKeyManager) Renew() (string, error) {
+	listenKey, err := m.Create()
+	if err != nil {
+		return "", err
+	}
+	logger().Infof("Listen key renewed")
+
+	m.mu.Lock()
+	callbacks := append([]func(string){}, m.onRenew...)
+	m.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(listenKey)
+	}
+	return listenKey, nil
+}
+
+// KeepAlive refreshes the current listen key
+func (m *ListenKeyManager) KeepAlive() error {
+	if _, err := m.client.UserData.UpdateListenKey(); err != nil {
+		return fmt.Errorf("error updating listen key: %v", err)
+	}
+	return nil
+}
+
+// Run refreshes the key on a timer until ctx is done. If a refresh fails
+// the key is assumed expired and is recreated.
+func (m *ListenKeyManager) Run(ctx context.Context) {
+	m.mu.Lock()
+	interval := m.interval
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.KeepAlive(); err != nil {
+				logger().Warnf("%v; recreating listen key", err)
+				if _, err := m.Renew(); err != nil {
+					logger().Warnf("Could not renew listen key: %v", err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close deletes the listen key
+func (m *ListenKeyManager) Close() error {
+	m.mu.Lock()
+	m.key = ""
+	m.mu.Unlock()
+
+	if _, err := m.client.UserData.DeleteListenKey(); err != nil {
+		return fmt.Errorf("error deleting listen key: %v", err)
+	}
+	return nil
+}
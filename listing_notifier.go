@@ -0,0 +1,76 @@
+package pi42
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// NewContractListed reports a contract that appeared in the exchange info
+type NewContractListed struct {
+	Contract   ContractInfo
+	DetectedAt time.Time
+}
+
+// ContractDelisted reports a contract that disappeared from the exchange info
+type ContractDelisted struct {
+	Contract   ContractInfo
+	DetectedAt time.Time
+}
+
+// ListingNotifier reports contracts listed or delisted between successive
+// exchange info refreshes. Refreshes are driven by RefreshExchangeInfo or
+// AutoRefreshExchangeInfo.
+type ListingNotifier struct {
+	onListed   []func(NewContractListed)
+	onDelisted []func(ContractDelisted)
+	mu         sync.Mutex
+}
+
+// NewListingNotifier creates a notifier for the client's exchange info
+// refreshes
+func NewListingNotifier(client *Client) *ListingNotifier {
+	n := &ListingNotifier{}
+	client.OnContractChange(n.handleChange)
+	return n
+}
+
+// OnListed registers a callback invoked for each newly listed contract
+func (n *ListingNotifier) OnListed(callback func(NewContractListed)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.onListed = append(n.onListed, callback)
+}
+
+// OnDelisted registers a callback invoked for each delisted contract
+func (n *ListingNotifier) OnDelisted(callback func(ContractDelisted)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.onDelisted = append(n.onDelisted, callback)
+}
+
+// handleChange dispatches contract additions and removals
+func (n *ListingNotifier) handleChange(change ContractChange) {
+	now := time.Now()
+
+	switch change.Kind {
+	case ContractAdded:
+		n.mu.Lock()
+		callbacks := slices.Clone(n.onListed)
+		n.mu.Unlock()
+
+		for _, callback := range callbacks {
+			callback(NewContractListed{Contract: change.Current, DetectedAt: now})
+		}
+	case ContractRemoved:
+		n.mu.Lock()
+		callbacks := slices.Clone(n.onDelisted)
+		n.mu.Unlock()
+
+		for _, callback := range callbacks {
+			callback(ContractDelisted{Contract: change.Previous, DetectedAt: now})
+		}
+	}
+}
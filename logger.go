@@ -0,0 +1,85 @@
+package pi42
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+// Log levels in increasing severity
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarning
+	LogError
+	// LogOff disables logging
+	LogOff
+)
+
+// Logger receives the library's log output, from both the REST client and
+// the streaming layer. Implement it to route messages into an application's
+// logging framework.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// stdLogger is a Logger writing to a standard library logger
+type stdLogger struct {
+	out   *log.Logger
+	level LogLevel
+}
+
+// NewStdLogger returns a Logger writing messages at or above level to out,
+// prefixed with their level. A nil out writes to the standard logger.
+func NewStdLogger(out *log.Logger, level LogLevel) Logger {
+	if out == nil {
+		out = log.Default()
+	}
+	return &stdLogger{out: out, level: level}
+}
+
+func (l *stdLogger) Debugf(format string, args ...any) { l.logf(LogDebug, "DEBUG", format, args) }
+func (l *stdLogger) Infof(format string, args ...any)  { l.logf(LogInfo, "INFO", format, args) }
+func (l *stdLogger) Warnf(format string, args ...any)  { l.logf(LogWarning, "WARN", format, args) }
+func (l *stdLogger) Errorf(format string, args ...any) { l.logf(LogError, "ERROR", format, args) }
+
+func (l *stdLogger) logf(level LogLevel, prefix, format string, args []any) {
+	if level < l.level {
+		return
+	}
+	l.out.Output(3, prefix+" "+fmt.Sprintf(format, args...))
+}
+
+var (
+	currentLogger Logger = NewStdLogger(nil, LogInfo)
+	loggerMutex   sync.RWMutex
+)
+
+// SetLogger sets the Logger used by every client in the package. Passing
+// nil restores the default, which writes info and above to the standard
+// logger. Call it before creating clients to also capture the exchange
+// info message logged by NewClient.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NewStdLogger(nil, LogInfo)
+	}
+
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+
+	currentLogger = l
+}
+
+// logger returns the current Logger
+func logger() Logger {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+
+	return currentLogger
+}
@@ -0,0 +1,148 @@
+package pi42
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarginAvailability is the margin of a margin asset free for new orders.
+// Amounts are in the margin asset.
+type MarginAvailability struct {
+	MarginAsset   string
+	WalletBalance float64
+	// Unrealized PnL of cross positions, which counts towards free margin
+	UnrealisedPnlCross float64
+	// Margin held by open positions and locked by open orders
+	PositionMargin float64
+	OrderMargin    float64
+	Available      float64
+}
+
+// InsufficientMarginError is returned when an order needs more margin than
+// is available
+type InsufficientMarginError struct {
+	MarginAsset string
+	Required    float64
+	Available   float64
+}
+
+// Error implements the error interface
+func (e InsufficientMarginError) Error() string {
+	return fmt.Sprintf("insufficient margin: order requires %v %s, %v available",
+		e.Required, e.MarginAsset, e.Available)
+}
+
+// Check returns an InsufficientMarginError if required exceeds the
+// available margin
+func (m MarginAvailability) Check(required float64) error {
+	if required > m.Available {
+		return InsufficientMarginError{
+			MarginAsset: m.MarginAsset,
+			Required:    required,
+			Available:   m.Available,
+		}
+	}
+	return nil
+}
+
+// AvailableMargin fetches the futures wallet, open positions and open
+// orders of a margin asset and computes the margin free for new orders
+func (c *Client) AvailableMargin(marginAsset string) (MarginAvailability, error) {
+	wallet, err := c.Wallet.FuturesWalletDetails(marginAsset)
+	if err != nil {
+		return MarginAvailability{}, err
+	}
+	balances, err := wallet.Balances()
+	if err != nil {
+		return MarginAvailability{}, err
+	}
+	positions, err := c.Position.GetPositions(PositionStatusOpen, PositionQueryParams{})
+	if err != nil {
+		return MarginAvailability{}, err
+	}
+	orders, err := c.Order.GetOpenOrders(OrderQueryParams{})
+	if err != nil {
+		return MarginAvailability{}, err
+	}
+
+	return ComputeAvailableMargin(balances, positions, orders, c.Converter())
+}
+
+// ComputeAvailableMargin computes the margin free for new orders from the
+// wallet balances and the open positions and orders, e.g. from local
+// caches. Positions and orders on other margin assets are ignored; margin
+// reported in the quote asset is converted to the margin asset.
+func ComputeAvailableMargin(wallet FuturesWalletBalances, positions []PositionResponse, orders []OpenOrder, converter *Converter) (MarginAvailability, error) {
+	asset := strings.ToUpper(wallet.MarginAsset)
+	m := MarginAvailability{
+		MarginAsset:        asset,
+		WalletBalance:      wallet.WalletBalance,
+		UnrealisedPnlCross: wallet.UnrealisedPnlCross,
+	}
+
+	for _, position := range positions {
+		if !strings.EqualFold(position.MarginAsset, asset) {
+			continue
+		}
+		if position.MarginInMarginAsset != 0 {
+			m.PositionMargin += position.MarginInMarginAsset
+			continue
+		}
+		rate, err := converter.Rate(position.QuoteAsset, asset)
+		if err != nil {
+			return MarginAvailability{}, fmt.Errorf("error valuing margin of position %s: %v", position.PositionID, err)
+		}
+		m.PositionMargin += position.Margin * rate
+	}
+
+	for _, order := range orders {
+		if order.ReduceOnly || (order.MarginAsset != "" && !strings.EqualFold(order.MarginAsset, asset)) {
+			continue
+		}
+		rate, err := converter.Rate(order.QuoteAsset, asset)
+		if err != nil {
+			return MarginAvailability{}, fmt.Errorf("error valuing margin of order %s: %v", order.ClientOrderID, err)
+		}
+		m.OrderMargin += order.LockedMargin * rate
+	}
+
+	m.Available = m.WalletBalance + m.UnrealisedPnlCross - m.PositionMargin - m.OrderMargin
+	if m.Available < 0 {
+		m.Available = 0
+	}
+	return m, nil
+}
+
+// RequiredMargin returns the margin, in marginAsset, an order of quantity
+// at price needs at leverage, including the contract's margin buffer
+func (c *Client) RequiredMargin(symbol string, quantity, price float64, leverage int, marginAsset string) (float64, error) {
+	contract, err := c.GetContractInfo(symbol)
+	if err != nil {
+		return 0, err
+	}
+	if leverage <= 0 {
+		return 0, fmt.Errorf("invalid leverage %d", leverage)
+	}
+
+	margin := quantity * price / float64(leverage) * (1 + contract.MarginBufferPercentage/100)
+	rate, err := c.Converter().Rate(contract.QuoteAsset, marginAsset)
+	if err != nil {
+		return 0, err
+	}
+	return margin * rate, nil
+}
+
+// CheckMargin reports whether an order of quantity at price and leverage
+// can be placed with the margin available in marginAsset, returning an
+// InsufficientMarginError if not
+func (c *Client) CheckMargin(symbol string, quantity, price float64, leverage int, marginAsset string) error {
+	required, err := c.RequiredMargin(symbol, quantity, price, leverage, marginAsset)
+	if err != nil {
+		return err
+	}
+	available, err := c.AvailableMargin(marginAsset)
+	if err != nil {
+		return err
+	}
+	return available.Check(required)
+}
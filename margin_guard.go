@@ -0,0 +1,233 @@
+package pi42
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTopUpCooldown is the minimum time between top-ups of a position
+// when AutoMarginConfig.Cooldown is not set
+const defaultTopUpCooldown = time.Minute
+
+// AutoMarginConfig configures AutoMarginGuard. At least one trigger must be set.
+type AutoMarginConfig struct {
+	// Top up when the margin ratio reaches this percentage (liquidation
+	// happens at 100); 0 disables the trigger
+	MaxMarginRatio float64
+	// Top up when the mark price is within this percentage of the
+	// liquidation price; 0 disables the trigger
+	MinLiquidationDistance float64
+	// Margin added per top-up, in the position's margin asset
+	TopUpAmount float64
+	// Total margin the guard may add to one position
+	MaxTopUpPerPosition float64
+	// Minimum time between top-ups of one position; defaults to one minute
+	Cooldown time.Duration
+}
+
+// MarginTopUp records a top-up made, or attempted, by AutoMarginGuard
+type MarginTopUp struct {
+	Time       time.Time
+	PositionID string
+	Symbol     string
+	Amount     float64
+	Reason     string
+	Risk       RiskReport // Risk at the time of the top-up
+	Err        error      // Non-nil if AddMargin failed
+}
+
+// AutoMarginGuard watches isolated positions and adds margin when their
+// margin ratio or distance to liquidation crosses the configured
+// thresholds, up to a limit per position. Every top-up is logged and kept
+// in an audit trail.
+type AutoMarginGuard struct {
+	client    *Client
+	positions *PositionCache
+	socket    *SocketClient
+	config    AutoMarginConfig
+
+	// Margin added and time of the last top-up per position ID
+	added    map[string]float64
+	lastAt   map[string]time.Time
+	inFlight map[string]bool
+	audit    []MarginTopUp
+	// Callbacks invoked after each top-up attempt
+	callbacks []func(MarginTopUp)
+	// Symbols with a mark price subscription
+	symbols map[string]bool
+	started bool
+	mu      sync.Mutex
+}
+
+// NewAutoMarginGuard creates a guard for the positions in a cache, reading
+// mark prices from socket
+func NewAutoMarginGuard(client *Client, positions *PositionCache, socket *SocketClient, config AutoMarginConfig) (*AutoMarginGuard, error) {
+	if config.MaxMarginRatio <= 0 && config.MinLiquidationDistance <= 0 {
+		return nil, fmt.Errorf("auto margin guard needs MaxMarginRatio or MinLiquidationDistance")
+	}
+	if config.TopUpAmount <= 0 || config.MaxTopUpPerPosition <= 0 {
+		return nil, fmt.Errorf("auto margin guard needs a positive TopUpAmount and MaxTopUpPerPosition")
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultTopUpCooldown
+	}
+
+	return &AutoMarginGuard{
+		client:    client,
+		positions: positions,
+		socket:    socket,
+		config:    config,
+		added:     make(map[string]float64),
+		lastAt:    make(map[string]time.Time),
+		inFlight:  make(map[string]bool),
+		symbols:   make(map[string]bool),
+	}, nil
+}
+
+// OnTopUp registers a callback invoked after each top-up attempt
+func (g *AutoMarginGuard) OnTopUp(callback func(MarginTopUp)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.callbacks = append(g.callbacks, callback)
+}
+
+// TopUps returns the audit trail of top-up attempts
+func (g *AutoMarginGuard) TopUps() []MarginTopUp {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return slices.Clone(g.audit)
+}
+
+// Start subscribes to the mark price of every open position, and of
+// positions opened later. The position cache must be started separately.
+func (g *AutoMarginGuard) Start() {
+	g.mu.Lock()
+	started := g.started
+	g.started = true
+	g.mu.Unlock()
+	if started {
+		return
+	}
+
+	g.positions.OnChange(func(event EventName, position PositionResponse) {
+		if event == UserEventClosePosition || position.PositionStatus == string(PositionStatusClosed) {
+			g.mu.Lock()
+			delete(g.added, position.PositionID)
+			delete(g.lastAt, position.PositionID)
+			g.mu.Unlock()
+			return
+		}
+		g.watch(position.ContractPair)
+	})
+	for _, position := range g.positions.All() {
+		g.watch(position.ContractPair)
+	}
+}
+
+// watch subscribes to a symbol's mark price once
+func (g *AutoMarginGuard) watch(symbol string) {
+	symbol = strings.ToUpper(symbol)
+
+	g.mu.Lock()
+	subscribed := g.symbols[symbol]
+	g.symbols[symbol] = true
+	g.mu.Unlock()
+
+	if !subscribed && symbol != "" {
+		g.socket.OnMarkPrice(symbol, g.check)
+	}
+}
+
+// check evaluates the isolated positions of a symbol at a mark price
+func (g *AutoMarginGuard) check(event MarkPriceEvent) {
+	markPrice, err := strconv.ParseFloat(event.MarkPrice, 64)
+	if err != nil || markPrice <= 0 {
+		return
+	}
+
+	for _, position := range g.positions.BySymbol(event.Symbol) {
+		if MarginMode(strings.ToUpper(position.MarginType)) != MarginModeIsolated {
+			continue
+		}
+		risk, err := g.client.RiskReport(position, markPrice)
+		if err != nil {
+			continue
+		}
+
+		var reason string
+		switch {
+		case g.config.MaxMarginRatio > 0 && risk.MarginRatio >= g.config.MaxMarginRatio:
+			reason = fmt.Sprintf("margin ratio %.2f%% reached %.2f%%", risk.MarginRatio, g.config.MaxMarginRatio)
+		case g.config.MinLiquidationDistance > 0 && risk.LiquidationPrice > 0 &&
+			risk.LiquidationDistance <= g.config.MinLiquidationDistance:
+			reason = fmt.Sprintf("liquidation distance %.2f%% within %.2f%%", risk.LiquidationDistance, g.config.MinLiquidationDistance)
+		default:
+			continue
+		}
+
+		if amount, ok := g.reserve(position.PositionID); ok {
+			// Top up off the socket's read goroutine
+			go g.topUp(position, amount, reason, risk)
+		}
+	}
+}
+
+// reserve returns the amount to add to a position, if it is not cooling
+// down, already being topped up or at its limit, and marks it in flight
+func (g *AutoMarginGuard) reserve(positionID string) (float64, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.inFlight[positionID] || time.Since(g.lastAt[positionID]) < g.config.Cooldown {
+		return 0, false
+	}
+	amount := min(g.config.TopUpAmount, g.config.MaxTopUpPerPosition-g.added[positionID])
+	if amount <= 0 {
+		return 0, false
+	}
+	g.inFlight[positionID] = true
+	return amount, true
+}
+
+// topUp adds margin to a position and records the attempt
+func (g *AutoMarginGuard) topUp(position PositionResponse, amount float64, reason string, risk RiskReport) {
+	_, err := g.client.Order.AddMargin(position.PositionID, amount)
+
+	record := MarginTopUp{
+		Time:       time.Now(),
+		PositionID: position.PositionID,
+		Symbol:     position.ContractPair,
+		Amount:     amount,
+		Reason:     reason,
+		Risk:       risk,
+		Err:        err,
+	}
+
+	g.mu.Lock()
+	delete(g.inFlight, position.PositionID)
+	g.lastAt[position.PositionID] = record.Time
+	if err == nil {
+		g.added[position.PositionID] += amount
+	}
+	g.audit = append(g.audit, record)
+	callbacks := slices.Clone(g.callbacks)
+	g.mu.Unlock()
+
+	if err != nil {
+		logger().Errorf("Margin top-up of %v %s for position %s failed (%s): %v",
+			amount, position.MarginAsset, position.PositionID, reason, err)
+	} else {
+		logger().Warnf("Added %v %s margin to position %s (%s)",
+			amount, position.MarginAsset, position.PositionID, reason)
+	}
+
+	for _, callback := range callbacks {
+		callback(record)
+	}
+}
@@ -0,0 +1,106 @@
+package pi42
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarginMode is the margin type of a contract: isolated or cross
+type MarginMode string
+
+// Supported margin modes
+const (
+	MarginModeIsolated MarginMode = "ISOLATED"
+	MarginModeCross    MarginMode = "CROSS"
+)
+
+// Validate returns an error if the margin mode is not supported
+func (m MarginMode) Validate() error {
+	switch m {
+	case MarginModeIsolated, MarginModeCross:
+		return nil
+	}
+	return fmt.Errorf("invalid margin mode %q (expected %s or %s)", m, MarginModeIsolated, MarginModeCross)
+}
+
+// MarginModeBlockedError is returned by SetMarginMode when open positions
+// or orders on the contract prevent changing its margin mode
+type MarginModeBlockedError struct {
+	Symbol        string
+	Current       MarginMode // Margin mode of the open positions
+	OpenPositions int
+	OpenOrders    int
+}
+
+// Error implements the error interface
+func (e MarginModeBlockedError) Error() string {
+	return fmt.Sprintf("cannot change margin mode of %s from %s: %d open positions and %d open orders",
+		e.Symbol, e.Current, e.OpenPositions, e.OpenOrders)
+}
+
+// MarginModeChange is the result of SetMarginMode
+type MarginModeChange struct {
+	Symbol     string
+	MarginMode MarginMode
+	Leverage   int
+	// False if the open positions already use the requested mode, in which
+	// case no request was sent
+	Changed bool
+}
+
+// SetMarginMode changes the margin mode of a contract. The change is
+// refused with a MarginModeBlockedError while the contract has open
+// positions in another mode or open orders. If leverage is 0, the leverage
+// of an open position is kept; it is required otherwise.
+func (api *ExchangeAPI) SetMarginMode(symbol string, mode MarginMode, leverage int) (*MarginModeChange, error) {
+	if err := mode.Validate(); err != nil {
+		return nil, err
+	}
+
+	positions, err := api.client.Position.GetPositions(PositionStatusOpen, PositionQueryParams{Symbol: symbol})
+	if err != nil {
+		return nil, fmt.Errorf("error checking open positions: %v", err)
+	}
+	orders, err := api.client.Order.GetOpenOrders(OrderQueryParams{Symbol: symbol})
+	if err != nil {
+		return nil, fmt.Errorf("error checking open orders: %v", err)
+	}
+
+	blocked := MarginModeBlockedError{Symbol: symbol}
+	for _, position := range positions {
+		if !strings.EqualFold(position.ContractPair, symbol) {
+			continue
+		}
+		blocked.OpenPositions++
+		blocked.Current = MarginMode(strings.ToUpper(position.MarginType))
+		if leverage == 0 {
+			leverage = position.Leverage
+		}
+	}
+	for _, order := range orders {
+		if strings.EqualFold(order.Symbol, symbol) {
+			blocked.OpenOrders++
+		}
+	}
+
+	if blocked.OpenPositions > 0 && blocked.Current == mode {
+		return &MarginModeChange{Symbol: symbol, MarginMode: mode, Leverage: leverage}, nil
+	}
+	if blocked.OpenPositions > 0 || blocked.OpenOrders > 0 {
+		return nil, blocked
+	}
+	if leverage <= 0 {
+		return nil, fmt.Errorf("leverage is required to set the margin mode of %s", symbol)
+	}
+
+	result, err := api.UpdatePreference(leverage, string(mode), symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &MarginModeChange{
+		Symbol:     symbol,
+		MarginMode: MarginMode(result.MarginMode),
+		Leverage:   result.UpdatedLeverage,
+		Changed:    true,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package pi42
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// MarginTier is a maintenance margin bracket of a contract. Positions with
+// a notional value between NotionalFloor and NotionalCap require
+// MaintMarginPercent of their notional as maintenance margin, less
+// MaintAmount.
+type MarginTier struct {
+	NotionalFloor      float64
+	NotionalCap        float64 // 0 means no upper bound
+	MaintMarginPercent float64 // In percent
+	MaintAmount        float64 // Deducted from the maintenance margin to keep brackets continuous
+	MaxLeverage        float64
+}
+
+// marginTierConfig is one entry of a contract's maintenanceMarginConfig.
+// Numbers may be sent as JSON numbers or numeric strings.
+type marginTierConfig struct {
+	NotionalFloor               json.Number `json:"notionalFloor"`
+	NotionalCap                 json.Number `json:"notionalCap"`
+	MaintenanceMarginPercentage json.Number `json:"maintenanceMarginPercentage"`
+	Cum                         json.Number `json:"cum"`
+	MaxLeverage                 json.Number `json:"maxLeverage"`
+}
+
+// parseMarginTiers decodes the maintenanceMarginConfig of a contract into
+// tiers sorted by notional floor. Entries that do not match the expected
+// schema are logged and skipped rather than guessed at.
+func parseMarginTiers(symbol string, config []interface{}) []MarginTier {
+	var tiers []MarginTier
+	for _, entry := range config {
+		tier, err := parseMarginTier(entry)
+		if err != nil {
+			logger().Warnf("Ignoring maintenance margin tier of %s: %v", symbol, err)
+			continue
+		}
+		tiers = append(tiers, tier)
+	}
+
+	slices.SortFunc(tiers, func(a, b MarginTier) int {
+		return cmp.Compare(a.NotionalFloor, b.NotionalFloor)
+	})
+	return tiers
+}
+
+// parseMarginTier decodes a single maintenanceMarginConfig entry
+func parseMarginTier(entry interface{}) (MarginTier, error) {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return MarginTier{}, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var config marginTierConfig
+	if err := decoder.Decode(&config); err != nil {
+		return MarginTier{}, fmt.Errorf("unexpected tier %s: %v", raw, err)
+	}
+	if config.MaintenanceMarginPercentage == "" {
+		return MarginTier{}, fmt.Errorf("tier %s has no maintenanceMarginPercentage", raw)
+	}
+
+	var tier MarginTier
+	for _, field := range []struct {
+		value json.Number
+		dest  *float64
+	}{
+		{config.NotionalFloor, &tier.NotionalFloor},
+		{config.NotionalCap, &tier.NotionalCap},
+		{config.MaintenanceMarginPercentage, &tier.MaintMarginPercent},
+		{config.Cum, &tier.MaintAmount},
+		{config.MaxLeverage, &tier.MaxLeverage},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if *field.dest, err = field.value.Float64(); err != nil {
+			return MarginTier{}, fmt.Errorf("invalid number in tier %s: %v", raw, err)
+		}
+	}
+	return tier, nil
+}
+
+// MaintenanceMargin returns the maintenance margin the tier requires for a
+// position of the given notional value
+func (t MarginTier) MaintenanceMargin(notional float64) float64 {
+	return math.Max(notional*t.MaintMarginPercent/100-t.MaintAmount, 0)
+}
+
+// LookupTier returns the maintenance margin tier whose bracket contains
+// notional, or the highest tier if notional exceeds every bracket. It
+// reports false if the contract has no tiers.
+func (ci ContractInfo) LookupTier(notional float64) (MarginTier, bool) {
+	tiers := ci.MarginTiers
+	if len(tiers) == 0 {
+		return MarginTier{}, false
+	}
+
+	for _, tier := range tiers {
+		if notional >= tier.NotionalFloor && (tier.NotionalCap == 0 || notional < tier.NotionalCap) {
+			return tier, true
+		}
+	}
+	// Tiers are sorted by floor, so the last one is the highest
+	return tiers[len(tiers)-1], true
+}
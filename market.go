@@ -3,12 +3,27 @@ package pi42
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
+// maxConcurrentTickerRequests bounds the number of parallel requests made by GetTickers
+const maxConcurrentTickerRequests = 8
+
 // MarketAPI provides access to market data endpoints
 type MarketAPI struct {
 	client *Client
+
+	// Optional cache for ticker and depth responses, nil when disabled
+	cache   *responseCache
+	cacheMu sync.RWMutex
+	// Coalesces concurrent cache misses for the same request
+	inflight singleflight.Group
 }
 
 // NewMarketAPI creates a new Market API instance
@@ -16,12 +31,67 @@ func NewMarketAPI(client *Client) *MarketAPI {
 	return &MarketAPI{client: client}
 }
 
+// SetCacheTTL enables caching of ticker and depth responses for the given
+// duration, so callers sharing a client don't refetch the same data many
+// times per second. A ttl of zero disables caching.
+func (api *MarketAPI) SetCacheTTL(ttl time.Duration) {
+	api.cacheMu.Lock()
+	defer api.cacheMu.Unlock()
+
+	if ttl <= 0 {
+		api.cache = nil
+		return
+	}
+	api.cache = newResponseCache(ttl)
+}
+
+// getCached performs a public GET request, serving it from the cache when enabled
+func (api *MarketAPI) getCached(endpoint string, params map[string]string) ([]byte, error) {
+	api.cacheMu.RLock()
+	cache := api.cache
+	api.cacheMu.RUnlock()
+
+	if cache == nil {
+		return api.client.Get(endpoint, params, true)
+	}
+
+	key := endpoint
+	if len(params) > 0 {
+		query := url.Values{}
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		key += "?" + query.Encode()
+	}
+
+	if data, ok := cache.get(key); ok {
+		return data, nil
+	}
+
+	// Callers missing the cache together share a single request
+	result, err, _ := api.inflight.Do(key, func() (interface{}, error) {
+		data, err := api.client.Get(endpoint, params, true)
+		if err != nil {
+			return nil, err
+		}
+		cache.set(key, data)
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
 // GetTicker24hr gets 24-hour ticker data for a specific trading pair
 func (api *MarketAPI) GetTicker24hr(contractPair string) (map[string]interface{}, error) {
-	endpoint := fmt.Sprintf("/v1/market/ticker24Hr/%s", strings.ToLower(contractPair))
-	params := make(map[string]string)
+	pair, err := api.client.Symbols.Resolve(contractPair)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/market/ticker24Hr/%s", strings.ToLower(pair))
 
-	data, err := api.client.Get(endpoint, params, true)
+	data, err := api.getCached(endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +106,11 @@ func (api *MarketAPI) GetTicker24hr(contractPair string) (map[string]interface{}
 
 // GetAggTrades gets aggregated trade data for a specific trading pair
 func (api *MarketAPI) GetAggTrades(contractPair string) (map[string]interface{}, error) {
-	endpoint := fmt.Sprintf("/v1/market/aggTrade/%s", strings.ToLower(contractPair))
+	pair, err := api.client.Symbols.Resolve(contractPair)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/market/aggTrade/%s", strings.ToLower(pair))
 
 	data, err := api.client.Get(endpoint, nil, true)
 	if err != nil {
@@ -51,12 +125,98 @@ func (api *MarketAPI) GetAggTrades(contractPair string) (map[string]interface{},
 	return result, nil
 }
 
+// GetRecentTrades gets the most recent trades for a specific trading pair.
+// limit is optional; pass 0 to use the exchange default.
+func (api *MarketAPI) GetRecentTrades(contractPair string, limit int) ([]Trade, error) {
+	pair, err := api.client.Symbols.Resolve(contractPair)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/market/trades/%s", strings.ToLower(pair))
+
+	params := make(map[string]string)
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+
+	data, err := api.client.Get(endpoint, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTrades(data)
+}
+
+// GetAggTradeList gets aggregated trades for a specific trading pair as typed trades
+func (api *MarketAPI) GetAggTradeList(contractPair string) ([]Trade, error) {
+	pair, err := api.client.Symbols.Resolve(contractPair)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/market/aggTrade/%s", strings.ToLower(pair))
+
+	data, err := api.client.Get(endpoint, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTrades(data)
+}
+
+// parseTrades converts a trades response body into typed trades
+func parseTrades(data []byte) ([]Trade, error) {
+	var response struct {
+		Data []AggTradeEvent `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing trades response: %v", err)
+	}
+
+	trades := make([]Trade, 0, len(response.Data))
+	for _, raw := range response.Data {
+		trade, err := raw.Trade()
+		if err != nil {
+			return nil, fmt.Errorf("could not parse trade %d: %v", raw.TradeID, err)
+		}
+		trades = append(trades, trade)
+	}
+
+	return trades, nil
+}
+
 // GetDepth gets order book depth data for a specific trading pair
 // Returns structured DepthResponse containing order book bids and asks
 func (api *MarketAPI) GetDepth(contractPair string) (*DepthResponse, error) {
-	endpoint := fmt.Sprintf("/v1/market/depth/%s", strings.ToLower(contractPair))
+	return api.GetDepthLimit(contractPair, 0)
+}
 
-	data, err := api.client.Get(endpoint, nil, true)
+// GetDepthLimit gets order book depth data limited to the best levels levels
+// per side, for callers that only need the top of the book. A levels value of
+// 0 returns the full book.
+func (api *MarketAPI) GetDepthLimit(contractPair string, levels int) (*DepthResponse, error) {
+	return api.getDepth(contractPair, levels, true)
+}
+
+// getDepth fetches order book depth, bypassing the response cache unless
+// cached is set. Order book snapshots must not be served stale.
+func (api *MarketAPI) getDepth(contractPair string, levels int, cached bool) (*DepthResponse, error) {
+	pair, err := api.client.Symbols.Resolve(contractPair)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/market/depth/%s", strings.ToLower(pair))
+
+	params := make(map[string]string)
+	if levels > 0 {
+		params["limit"] = strconv.Itoa(levels)
+	}
+
+	var data []byte
+	if cached {
+		data, err = api.getCached(endpoint, params)
+	} else {
+		data, err = api.client.Get(endpoint, params, true)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,16 +226,26 @@ func (api *MarketAPI) GetDepth(contractPair string) (*DepthResponse, error) {
 		return nil, fmt.Errorf("error parsing depth response: %v", err)
 	}
 
+	// Trim locally in case the exchange returned more levels than requested
+	if levels > 0 {
+		if len(result.Data.Bids) > levels {
+			result.Data.Bids = result.Data.Bids[:levels]
+		}
+		if len(result.Data.Asks) > levels {
+			result.Data.Asks = result.Data.Asks[:levels]
+		}
+	}
+
 	return &result, nil
 }
 
 // KlinesParams represents parameters for the Klines method
 type KlinesParams struct {
-	Pair      string `json:"pair"`                // Trading pair (e.g., "BTCINR")
-	Interval  string `json:"interval"`            // Kline interval (e.g., "1m", "1h", "1d")
-	StartTime int64  `json:"startTime,omitempty"` // Optional start time in milliseconds
-	EndTime   int64  `json:"endTime,omitempty"`   // Optional end time in milliseconds
-	Limit     int    `json:"limit,omitempty"`     // Optional limit on number of results
+	Pair      string        `json:"pair"`                // Trading pair (e.g., "BTCINR")
+	Interval  KlineInterval `json:"interval"`            // Kline interval (e.g., Interval1m, Interval1h)
+	StartTime int64         `json:"startTime,omitempty"` // Optional start time in milliseconds
+	EndTime   int64         `json:"endTime,omitempty"`   // Optional end time in milliseconds
+	Limit     int           `json:"limit,omitempty"`     // Optional limit on number of results
 }
 
 // GetKlines gets candlestick (kline) data for a specific trading pair and interval
@@ -83,10 +253,19 @@ type KlinesParams struct {
 func (api *MarketAPI) GetKlines(params KlinesParams) ([]KlineData, error) {
 	endpoint := "/v1/market/klines"
 
+	interval, err := ParseKlineInterval(string(params.Interval))
+	if err != nil {
+		return nil, err
+	}
+	pair, err := api.client.Symbols.Resolve(params.Pair)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert struct to map for the request
 	paramsMap := map[string]interface{}{
-		"pair":     strings.ToUpper(params.Pair),
-		"interval": strings.ToLower(params.Interval),
+		"pair":     pair,
+		"interval": interval,
 	}
 
 	if params.StartTime > 0 {
@@ -115,4 +294,64 @@ func (api *MarketAPI) GetKlines(params KlinesParams) ([]KlineData, error) {
 // For backward compatibility
 func (api *MarketAPI) Ticker24Hr(contractPair string) (map[string]interface{}, error) {
 	return api.GetTicker24hr(contractPair)
-}
\ No newline at end of file
+}
+
+// TickerResponse represents the response from the 24hr ticker endpoint
+type TickerResponse struct {
+	Data Ticker `json:"data"`
+}
+
+// GetTicker gets 24-hour ticker data for a specific trading pair as a typed Ticker
+func (api *MarketAPI) GetTicker(contractPair string) (*Ticker, error) {
+	pair, err := api.client.Symbols.Resolve(contractPair)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("/v1/market/ticker24Hr/%s", strings.ToLower(pair))
+
+	data, err := api.getCached(endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result TickerResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error parsing ticker response: %v", err)
+	}
+
+	return &result.Data, nil
+}
+
+// GetTickers fetches 24-hour tickers for many trading pairs in parallel.
+// Successful results are keyed by symbol; symbols that failed are reported
+// in the returned error map instead.
+func (api *MarketAPI) GetTickers(symbols []string) (map[string]Ticker, map[string]error) {
+	tickers := make(map[string]Ticker, len(symbols))
+	errs := make(map[string]error)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentTickerRequests)
+
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			ticker, err := api.GetTicker(symbol)
+			<-sem
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[symbol] = err
+				return
+			}
+			tickers[symbol] = *ticker
+		}(symbol)
+	}
+
+	wg.Wait()
+	return tickers, errs
+}
@@ -1,5 +1,82 @@
 package pi42
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KlineInterval represents a candlestick interval
+type KlineInterval string
+
+// Supported kline intervals
+const (
+	Interval1m  KlineInterval = "1m"
+	Interval3m  KlineInterval = "3m"
+	Interval5m  KlineInterval = "5m"
+	Interval15m KlineInterval = "15m"
+	Interval30m KlineInterval = "30m"
+	Interval1h  KlineInterval = "1h"
+	Interval2h  KlineInterval = "2h"
+	Interval4h  KlineInterval = "4h"
+	Interval6h  KlineInterval = "6h"
+	Interval8h  KlineInterval = "8h"
+	Interval12h KlineInterval = "12h"
+	Interval1d  KlineInterval = "1d"
+	Interval3d  KlineInterval = "3d"
+	Interval1w  KlineInterval = "1w"
+	Interval1M  KlineInterval = "1M"
+)
+
+// klineIntervalDurations maps each supported interval to its length.
+// Monthly candles have no fixed length and are approximated as 30 days.
+var klineIntervalDurations = map[KlineInterval]time.Duration{
+	Interval1m:  time.Minute,
+	Interval3m:  3 * time.Minute,
+	Interval5m:  5 * time.Minute,
+	Interval15m: 15 * time.Minute,
+	Interval30m: 30 * time.Minute,
+	Interval1h:  time.Hour,
+	Interval2h:  2 * time.Hour,
+	Interval4h:  4 * time.Hour,
+	Interval6h:  6 * time.Hour,
+	Interval8h:  8 * time.Hour,
+	Interval12h: 12 * time.Hour,
+	Interval1d:  24 * time.Hour,
+	Interval3d:  3 * 24 * time.Hour,
+	Interval1w:  7 * 24 * time.Hour,
+	Interval1M:  30 * 24 * time.Hour,
+}
+
+// ParseKlineInterval converts a string into a supported KlineInterval.
+// Intervals are case-sensitive only where needed to tell 1m from 1M.
+func ParseKlineInterval(s string) (KlineInterval, error) {
+	interval := KlineInterval(s)
+	if _, ok := klineIntervalDurations[interval]; ok {
+		return interval, nil
+	}
+
+	interval = KlineInterval(strings.ToLower(s))
+	if _, ok := klineIntervalDurations[interval]; ok {
+		return interval, nil
+	}
+
+	return "", fmt.Errorf("unsupported kline interval %q", s)
+}
+
+// Validate returns an error if the interval is not supported by the exchange
+func (i KlineInterval) Validate() error {
+	if _, ok := klineIntervalDurations[i]; !ok {
+		return fmt.Errorf("unsupported kline interval %q", string(i))
+	}
+	return nil
+}
+
+// Duration returns the length of the interval, or 0 if it is not supported
+func (i KlineInterval) Duration() time.Duration {
+	return klineIntervalDurations[i]
+}
+
 // DepthResponse represents the full response from the GetDepth endpoint
 type DepthResponse struct {
 	Data DepthData `json:"data"`
@@ -28,3 +105,34 @@ type KlineData struct {
 	EndTime   string `json:"endTime"`   // End time of the interval in milliseconds
 	Volume    string `json:"volume"`    // Trading volume during the interval
 }
+
+// Ticker represents 24-hour rolling window statistics for a single symbol,
+// as delivered by the 24hrTicker and tickerArr WebSocket events
+type Ticker struct {
+	EventType          string `json:"e"` // Event type (24hrTicker)
+	EventTime          int64  `json:"E"` // Event time in milliseconds
+	Symbol             string `json:"s"` // Trading pair symbol
+	PriceChange        string `json:"p"` // Absolute price change
+	PriceChangePercent string `json:"P"` // Price change percent
+	WeightedAvgPrice   string `json:"w"` // Weighted average price
+	LastPrice          string `json:"c"` // Last traded price
+	LastQuantity       string `json:"Q"` // Last traded quantity
+	OpenPrice          string `json:"o"` // Open price of the window
+	HighPrice          string `json:"h"` // Highest price of the window
+	LowPrice           string `json:"l"` // Lowest price of the window
+	Volume             string `json:"v"` // Total traded base asset volume
+	QuoteVolume        string `json:"q"` // Total traded quote asset volume
+	OpenTime           int64  `json:"O"` // Window open time in milliseconds
+	CloseTime          int64  `json:"C"` // Window close time in milliseconds
+	TradeCount         int64  `json:"n"` // Number of trades in the window
+}
+
+// Trade represents a single executed trade with parsed values
+type Trade struct {
+	ID       int64     // Trade ID
+	Symbol   string    // Trading pair symbol
+	Price    float64   // Trade price
+	Quantity float64   // Trade quantity
+	Side     OrderSide // Taker side; SELL when the buyer was the maker
+	Time     time.Time // Execution time
+}
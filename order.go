@@ -3,7 +3,6 @@ package pi42
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"strconv"
 )
@@ -32,9 +31,11 @@ type PlaceOrderParams struct {
 	StopLossPrice   float64   `json:"stopLossPrice,omitempty"`
 	StopPrice       float64   `json:"stopPrice,omitempty"`
 	PositionID      string    `json:"positionId,omitempty"`
-	DeviceType      string    `json:"deviceType"`
-	UserCategory    string    `json:"userCategory"`
-	Leverage        int       `json:"leverage,omitempty"`
+	// Position to open or reduce in hedge mode; leave empty in one-way mode
+	PositionSide PositionSide `json:"positionSide,omitempty"`
+	DeviceType   string       `json:"deviceType"`
+	UserCategory string       `json:"userCategory"`
+	Leverage     int          `json:"leverage,omitempty"`
 }
 
 // OrderResponse represents the structured response when placing an order
@@ -63,10 +64,24 @@ type OrderResponse struct {
 	StopPrice           float64 `json:"stopPrice"`
 }
 
-// PlaceOrder places an order on Pi42's trading platform
+// PlaceOrder places an order on Pi42's trading platform. Orders failing the
+// contract's filters are rejected locally with an OrderValidationError.
 func (api *OrderAPI) PlaceOrder(params PlaceOrderParams) (OrderResponse, error) {
 	endpoint := "/v1/order/place-order"
 
+	symbol, err := api.client.Symbols.Resolve(params.Symbol)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	params.Symbol = symbol
+
+	// Check the exchange filters locally when the contract is known
+	if contract, err := api.client.GetContractInfo(symbol); err == nil {
+		if violations := contract.ValidateOrder(params); len(violations) > 0 {
+			return OrderResponse{}, OrderValidationError{Symbol: symbol, Violations: violations}
+		}
+	}
+
 	// Convert struct to map for the request
 	paramsMap := map[string]interface{}{
 		"symbol":      params.Symbol,
@@ -111,6 +126,10 @@ func (api *OrderAPI) PlaceOrder(params PlaceOrderParams) (OrderResponse, error)
 		paramsMap["leverage"] = params.Leverage
 	}
 
+	if params.PositionSide != "" {
+		paramsMap["positionSide"] = params.PositionSide
+	}
+
 	data, err := api.client.Post(endpoint, paramsMap, false)
 	if err != nil {
 		return OrderResponse{}, err
@@ -354,10 +373,11 @@ type BulletParams struct {
 // and returns a structured order response
 func (api *OrderAPI) Bullet(params BulletParams) (*OrderResponse, error) {
 	// Get contract info for the symbol
-	contractInfo, ok := api.client.ExchangeInfo[params.Symbol]
-	if !ok {
-		return nil, fmt.Errorf("symbol %s not found in exchange info", params.Symbol)
+	contractInfo, err := api.client.GetContractInfo(params.Symbol)
+	if err != nil {
+		return nil, err
 	}
+	params.Symbol = contractInfo.Symbol
 
 	// Validate order type
 	validOrderTypes := []OrderType{"MARKET", "LIMIT", "STOP_MARKET", "STOP_LIMIT"}
@@ -396,7 +416,7 @@ func (api *OrderAPI) Bullet(params BulletParams) (*OrderResponse, error) {
 
 	// If min quantity is not set (could happen if filter parsing failed), use a safe default
 	if minQuantity <= 0 {
-		log.Default().Printf("Warning: Minimum quantity for %s not set, using default value\n", params.Symbol)
+		logger().Warnf("Minimum quantity for %s not set, using default value", params.Symbol)
 		minQuantity = 0.001 // Default fallback
 	}
 
@@ -451,17 +471,17 @@ func (api *OrderAPI) Bullet(params BulletParams) (*OrderResponse, error) {
 		PositionID:  params.PositionID,
 	}
 
-	// For limit orders, round the price to the correct precision
+	// For limit orders, round the price to the tick size
 	if (params.OrderType == "LIMIT" || params.OrderType == "STOP_LIMIT") && params.Price > 0 {
-		orderParams.Price = roundToDecimal(params.Price, contractInfo.PricePrecision)
+		orderParams.Price = contractInfo.RoundToTick(params.Price)
 	}
 
 	// For stop orders, set the stop price
 	if (params.OrderType == "STOP_MARKET" || params.OrderType == "STOP_LIMIT") && params.StopPrice > 0 {
-		orderParams.StopPrice = roundToDecimal(params.StopPrice, contractInfo.PricePrecision)
+		orderParams.StopPrice = contractInfo.RoundToTick(params.StopPrice)
 	}
 
-	log.Default().Printf("Placing order with params: %+v\n", orderParams)
+	logger().Debugf("Placing order with params: %+v", orderParams)
 
 	// Place the order using the standard PlaceOrder method
 	responseMap, err := api.PlaceOrder(orderParams)
@@ -487,10 +507,11 @@ func (api *OrderAPI) Bullet(params BulletParams) (*OrderResponse, error) {
 }
 func (api *OrderAPI) BulletMap(params BulletParams) (OrderResponse, error) {
 	// Get contract info for the symbol
-	contractInfo, ok := api.client.ExchangeInfo[params.Symbol]
-	if !ok {
-		return OrderResponse{}, fmt.Errorf("symbol %s not found in exchange info", params.Symbol)
+	contractInfo, err := api.client.GetContractInfo(params.Symbol)
+	if err != nil {
+		return OrderResponse{}, err
 	}
+	params.Symbol = contractInfo.Symbol
 
 	// Validate order type
 	validOrderTypes := []OrderType{"MARKET", "LIMIT", "STOP_MARKET", "STOP_LIMIT"}
@@ -529,7 +550,7 @@ func (api *OrderAPI) BulletMap(params BulletParams) (OrderResponse, error) {
 
 	// If min quantity is not set (could happen if filter parsing failed), use a safe default
 	if minQuantity <= 0 {
-		log.Default().Printf("Warning: Minimum quantity for %s not set, using default value\n", params.Symbol)
+		logger().Warnf("Minimum quantity for %s not set, using default value", params.Symbol)
 		minQuantity = 0.001 // Default fallback
 	}
 
@@ -585,17 +606,17 @@ func (api *OrderAPI) BulletMap(params BulletParams) (OrderResponse, error) {
 		Leverage:    params.Leverage,
 	}
 
-	// For limit orders, round the price to the correct precision
+	// For limit orders, round the price to the tick size
 	if (params.OrderType == "LIMIT" || params.OrderType == "STOP_LIMIT") && params.Price > 0 {
-		orderParams.Price = roundToDecimal(params.Price, contractInfo.PricePrecision)
+		orderParams.Price = contractInfo.RoundToTick(params.Price)
 	}
 
 	// For stop orders, set the stop price
 	if (params.OrderType == "STOP_MARKET" || params.OrderType == "STOP_LIMIT") && params.StopPrice > 0 {
-		orderParams.StopPrice = roundToDecimal(params.StopPrice, contractInfo.PricePrecision)
+		orderParams.StopPrice = contractInfo.RoundToTick(params.StopPrice)
 	}
 
-	log.Default().Printf("Placing order with params: %+v\n", orderParams)
+	logger().Debugf("Placing order with params: %+v", orderParams)
 
 	// Place the order using the standard PlaceOrder method
 	return api.PlaceOrder(orderParams)
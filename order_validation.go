@@ -0,0 +1,150 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Rules checked by ValidateOrder
+const (
+	RuleOrderType     = "ORDER_TYPE"
+	RulePriceRequired = "PRICE_REQUIRED"
+	RuleQuantity      = "QTY_SIZE"
+	RuleMinNotional   = "MIN_NOTIONAL"
+	RulePriceVariance = "PRICE_VARIANCE"
+	RulePrecision     = "PRECISION"
+)
+
+// OrderViolation is a single exchange filter an order fails
+type OrderViolation struct {
+	Rule  string // One of the Rule constants
+	Field string // Order field at fault, e.g. "quantity" or "price"
+	Value float64
+	// Bound the value violates; zero when not applicable
+	Limit   float64
+	Message string
+}
+
+// OrderValidationError lists the filters an order fails
+type OrderValidationError struct {
+	Symbol     string
+	Violations []OrderViolation
+}
+
+// Error implements the error interface
+func (e OrderValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return fmt.Sprintf("invalid %s order: %s", e.Symbol, strings.Join(messages, "; "))
+}
+
+// ValidateOrder checks an order against the contract's filters: supported
+// order types, required prices, quantity bounds, minimum notional and
+// price and quantity precision. Market orders have no price, so their
+// notional is not checked; use ValidateOrderAt to check it and the limit
+// price variance against a mark price.
+func (ci ContractInfo) ValidateOrder(params PlaceOrderParams) []OrderViolation {
+	return ci.ValidateOrderAt(params, 0)
+}
+
+// ValidateOrderAt checks an order like ValidateOrder, also valuing market
+// orders at markPrice and checking limit prices against the allowed
+// deviation from it. A markPrice of 0 skips those checks.
+func (ci ContractInfo) ValidateOrderAt(params PlaceOrderParams, markPrice float64) []OrderViolation {
+	var violations []OrderViolation
+	add := func(rule, field string, value, limit float64, format string, args ...any) {
+		violations = append(violations, OrderViolation{
+			Rule:    rule,
+			Field:   field,
+			Value:   value,
+			Limit:   limit,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	isMarket := params.Type == OrderTypeMarket || params.Type == OrderTypeStopMarket
+	isStop := params.Type == OrderTypeStopMarket || params.Type == OrderTypeStopLimit
+
+	// Order type, with stop orders checked by their base type
+	baseType := OrderTypeLimit
+	if isMarket {
+		baseType = OrderTypeMarket
+	}
+	switch params.Type {
+	case OrderTypeMarket, OrderTypeLimit, OrderTypeStopMarket, OrderTypeStopLimit:
+		if len(ci.OrderTypes) > 0 && !slices.Contains(ci.OrderTypes, baseType) {
+			add(RuleOrderType, "type", 0, 0, "order type %s not supported", params.Type)
+		}
+		if !isMarket && params.Price <= 0 {
+			add(RulePriceRequired, "price", params.Price, 0, "price is required for %s orders", params.Type)
+		}
+		if isStop && params.StopPrice <= 0 {
+			add(RulePriceRequired, "stopPrice", params.StopPrice, 0, "stop price is required for %s orders", params.Type)
+		}
+	default:
+		add(RuleOrderType, "type", 0, 0, "invalid order type %q", params.Type)
+	}
+
+	// Quantity bounds of the order type
+	minQuantity, maxQuantity := ci.MinQuantity, ci.MaxQuantity
+	if isMarket && (ci.MarketMinQuantity > 0 || ci.MarketMaxQuantity > 0) {
+		minQuantity, maxQuantity = ci.MarketMinQuantity, ci.MarketMaxQuantity
+	}
+	if params.Quantity <= 0 {
+		add(RuleQuantity, "quantity", params.Quantity, 0, "quantity must be greater than 0")
+	} else if minQuantity > 0 && params.Quantity < minQuantity {
+		add(RuleQuantity, "quantity", params.Quantity, minQuantity, "quantity %v below minimum %v", params.Quantity, minQuantity)
+	} else if maxQuantity > 0 && params.Quantity > maxQuantity {
+		add(RuleQuantity, "quantity", params.Quantity, maxQuantity, "quantity %v above maximum %v", params.Quantity, maxQuantity)
+	}
+
+	// Notional at the order price, or the mark price for market orders.
+	// Reduce-only orders are exempt so small positions can be closed.
+	price := params.Price
+	if isMarket {
+		price = markPrice
+	}
+	if notional := params.Quantity * price; ci.MinNotional > 0 && notional > 0 && !params.ReduceOnly && notional < ci.MinNotional {
+		add(RuleMinNotional, "quantity", notional, ci.MinNotional, "order value %v below minimum notional %v", notional, ci.MinNotional)
+	}
+
+	if !isMarket && markPrice > 0 && params.Price > 0 && ci.LimitPriceVariance > 0 {
+		if deviation := math.Abs(params.Price-markPrice) / markPrice; deviation > ci.LimitPriceVariance {
+			add(RulePriceVariance, "price", params.Price, ci.LimitPriceVariance,
+				"price %v deviates %.2f%% from mark price %v (max %.2f%%)",
+				params.Price, deviation*100, markPrice, ci.LimitPriceVariance*100)
+		}
+	}
+
+	// Precision
+	if params.Quantity != 0 && !hasPrecision(params.Quantity, ci.QuantityPrecision) {
+		add(RulePrecision, "quantity", params.Quantity, float64(ci.QuantityPrecision),
+			"quantity %v has more than %d decimals", params.Quantity, ci.QuantityPrecision)
+	}
+	for _, field := range []struct {
+		name  string
+		value float64
+	}{
+		{"price", params.Price},
+		{"stopPrice", params.StopPrice},
+		{"takeProfitPrice", params.TakeProfitPrice},
+		{"stopLossPrice", params.StopLossPrice},
+	} {
+		if field.value != 0 && !ci.onTick(field.value) {
+			add(RulePrecision, field.name, field.value, ci.PriceStep(),
+				"%s %v is not a multiple of the price step %v", field.name, field.value, ci.PriceStep())
+		}
+	}
+
+	return violations
+}
+
+// hasPrecision reports whether value has at most precision decimals
+func hasPrecision(value float64, precision int) bool {
+	rounded := roundToDecimal(value, precision)
+	return math.Abs(value-rounded) <= 1e-9*math.Max(1, math.Abs(value))
+}
@@ -0,0 +1,207 @@
+package pi42
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PriceLevel represents a single price level in an order book
+type PriceLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBook is a thread-safe local copy of a symbol's order book that can be
+// seeded from a REST depth snapshot and kept current from depthUpdate events
+type OrderBook struct {
+	Symbol string
+
+	bids         map[float64]float64
+	asks         map[float64]float64
+	lastUpdateID int64
+	updatedAt    time.Time
+	mu           sync.RWMutex
+}
+
+// NewOrderBook creates an empty order book for a symbol
+func NewOrderBook(symbol string) *OrderBook {
+	return &OrderBook{
+		Symbol: symbol,
+		bids:   make(map[float64]float64),
+		asks:   make(map[float64]float64),
+	}
+}
+
+// ApplySnapshot replaces the book contents with the given depth data
+func (ob *OrderBook) ApplySnapshot(depth DepthData) error {
+	bids, err := parseLevels(depth.Bids)
+	if err != nil {
+		return fmt.Errorf("could not parse bids: %v", err)
+	}
+	asks, err := parseLevels(depth.Asks)
+	if err != nil {
+		return fmt.Errorf("could not parse asks: %v", err)
+	}
+
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.bids = make(map[float64]float64, len(bids))
+	ob.asks = make(map[float64]float64, len(asks))
+	for _, level := range bids {
+		if level.Quantity > 0 {
+			ob.bids[level.Price] = level.Quantity
+		}
+	}
+	for _, level := range asks {
+		if level.Quantity > 0 {
+			ob.asks[level.Price] = level.Quantity
+		}
+	}
+	ob.lastUpdateID = depth.LastUpdateID
+	ob.updatedAt = time.Now()
+	return nil
+}
+
+// ApplyUpdate merges incremental depth data into the book. Levels with a zero
+// quantity are removed. Updates older than the current book are ignored.
+func (ob *OrderBook) ApplyUpdate(depth DepthData) error {
+	bids, err := parseLevels(depth.Bids)
+	if err != nil {
+		return fmt.Errorf("could not parse bids: %v", err)
+	}
+	asks, err := parseLevels(depth.Asks)
+	if err != nil {
+		return fmt.Errorf("could not parse asks: %v", err)
+	}
+
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if depth.LastUpdateID > 0 && depth.LastUpdateID <= ob.lastUpdateID {
+		return nil // Stale update
+	}
+
+	applyLevels(ob.bids, bids)
+	applyLevels(ob.asks, asks)
+	if depth.LastUpdateID > 0 {
+		ob.lastUpdateID = depth.LastUpdateID
+	}
+	ob.updatedAt = time.Now()
+	return nil
+}
+
+// ApplyEvent applies a depthUpdate WebSocket event to the book
+func (ob *OrderBook) ApplyEvent(event EventData) error {
+	if depth, ok := event.Payload.(*DepthUpdateEvent); ok {
+		return ob.ApplyUpdate(depth.DepthData)
+	}
+
+	var depth DepthData
+	if err := decodeEventPayload(event.Data, &depth); err != nil {
+		return err
+	}
+	return ob.ApplyUpdate(depth)
+}
+
+// Bids returns up to n bid levels ordered from best to worst; n <= 0 returns all
+func (ob *OrderBook) Bids(n int) []PriceLevel {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return sortedLevels(ob.bids, n, true)
+}
+
+// Asks returns up to n ask levels ordered from best to worst; n <= 0 returns all
+func (ob *OrderBook) Asks(n int) []PriceLevel {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return sortedLevels(ob.asks, n, false)
+}
+
+// BestBid returns the highest bid level
+func (ob *OrderBook) BestBid() (PriceLevel, bool) {
+	levels := ob.Bids(1)
+	if len(levels) == 0 {
+		return PriceLevel{}, false
+	}
+	return levels[0], true
+}
+
+// BestAsk returns the lowest ask level
+func (ob *OrderBook) BestAsk() (PriceLevel, bool) {
+	levels := ob.Asks(1)
+	if len(levels) == 0 {
+		return PriceLevel{}, false
+	}
+	return levels[0], true
+}
+
+// LastUpdateID returns the update ID of the most recently applied depth data
+func (ob *OrderBook) LastUpdateID() int64 {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return ob.lastUpdateID
+}
+
+// UpdatedAt returns the time the book was last changed
+func (ob *OrderBook) UpdatedAt() time.Time {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return ob.updatedAt
+}
+
+// parseLevels converts [price, quantity] string pairs into price levels
+func parseLevels(raw [][]string) ([]PriceLevel, error) {
+	levels := make([]PriceLevel, 0, len(raw))
+	for _, entry := range raw {
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("malformed price level: %v", entry)
+		}
+		price, err := strconv.ParseFloat(entry[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := strconv.ParseFloat(entry[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, PriceLevel{Price: price, Quantity: quantity})
+	}
+	return levels, nil
+}
+
+// applyLevels sets or removes levels in a side of the book
+func applyLevels(side map[float64]float64, levels []PriceLevel) {
+	for _, level := range levels {
+		if level.Quantity == 0 {
+			delete(side, level.Price)
+		} else {
+			side[level.Price] = level.Quantity
+		}
+	}
+}
+
+// sortedLevels returns up to n levels of a side, best price first
+func sortedLevels(side map[float64]float64, n int, descending bool) []PriceLevel {
+	levels := make([]PriceLevel, 0, len(side))
+	for price, quantity := range side {
+		levels = append(levels, PriceLevel{Price: price, Quantity: quantity})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	if n > 0 && len(levels) > n {
+		levels = levels[:n]
+	}
+	return levels
+}
@@ -0,0 +1,103 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultMetricsInterval is how often MetricsStream emits when no positive
+// interval is given
+const defaultMetricsInterval = time.Second
+
+// BookMetrics holds liquidity and imbalance statistics for an order book
+type BookMetrics struct {
+	Time       time.Time
+	BestBid    float64
+	BestAsk    float64
+	MidPrice   float64
+	Spread     float64 // Absolute bid/ask spread
+	Microprice float64 // Mid price weighted by top-of-book quantities
+	BidDepth   float64 // Total bid quantity over the measured levels
+	AskDepth   float64 // Total ask quantity over the measured levels
+	DepthRatio float64 // BidDepth / AskDepth
+	Imbalance  float64 // (BidDepth - AskDepth) / (BidDepth + AskDepth), in [-1, 1]
+	Levels     int     // Number of levels per side used for depth figures
+}
+
+// Metrics computes liquidity metrics over the top levels of each side of the book
+func (ob *OrderBook) Metrics(levels int) (BookMetrics, error) {
+	if levels < 1 {
+		return BookMetrics{}, fmt.Errorf("levels must be at least 1, got %d", levels)
+	}
+
+	bids := ob.Bids(levels)
+	asks := ob.Asks(levels)
+	if len(bids) == 0 || len(asks) == 0 {
+		return BookMetrics{}, fmt.Errorf("order book for %s is empty", ob.Symbol)
+	}
+
+	m := BookMetrics{
+		Time:    time.Now(),
+		BestBid: bids[0].Price,
+		BestAsk: asks[0].Price,
+		Levels:  levels,
+	}
+	m.MidPrice = (m.BestBid + m.BestAsk) / 2
+	m.Spread = m.BestAsk - m.BestBid
+
+	topBidQty, topAskQty := bids[0].Quantity, asks[0].Quantity
+	m.Microprice = (m.BestBid*topAskQty + m.BestAsk*topBidQty) / (topBidQty + topAskQty)
+
+	for _, level := range bids {
+		m.BidDepth += level.Quantity
+	}
+	for _, level := range asks {
+		m.AskDepth += level.Quantity
+	}
+	if m.AskDepth > 0 {
+		m.DepthRatio = m.BidDepth / m.AskDepth
+	}
+	if total := m.BidDepth + m.AskDepth; total > 0 {
+		m.Imbalance = (m.BidDepth - m.AskDepth) / total
+	}
+
+	return m, nil
+}
+
+// MetricsStream emits book metrics at a fixed interval until ctx is cancelled.
+// Ticks where the book is empty are skipped; if the consumer is slower than
+// the interval, intermediate values are dropped rather than queued. A
+// non-positive interval falls back to one second.
+func (ob *OrderBook) MetricsStream(ctx context.Context, interval time.Duration, levels int) <-chan BookMetrics {
+	if interval <= 0 {
+		interval = defaultMetricsInterval
+	}
+	out := make(chan BookMetrics, 1)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				metrics, err := ob.Metrics(levels)
+				if err != nil {
+					continue
+				}
+				select {
+				case out <- metrics:
+				default:
+					// Previous value not consumed yet
+				}
+			}
+		}
+	}()
+
+	return out
+}
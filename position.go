@@ -3,7 +3,9 @@ package pi42
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // PositionAPI provides access to position management endpoints
@@ -23,6 +25,8 @@ type PositionQueryParams struct {
 	SortOrder      string `json:"sortOrder,omitempty"`
 	PageSize       int    `json:"pageSize,omitempty"`
 	Symbol         string `json:"symbol,omitempty"`
+	// Only return positions on this side, e.g. in hedge mode
+	PositionSide PositionSide `json:"positionSide,omitempty"`
 }
 
 // Position represents a trading position
@@ -86,9 +90,39 @@ func (api *PositionAPI) GetPositions(positionStatus PositionStatus, params Posit
 		return nil, fmt.Errorf("error parsing response: %v", err)
 	}
 
+	// Long and short positions on one contract are separate entries in
+	// hedge mode
+	if params.PositionSide != "" && params.PositionSide != PositionSideBoth {
+		filtered := result[:0]
+		for _, position := range result {
+			if position.Side() == params.PositionSide {
+				filtered = append(filtered, position)
+			}
+		}
+		result = filtered
+	}
+
 	return result, nil
 }
 
+// GetOpenPositionBySymbol returns the open position on a contract pair, or
+// nil if there is none. In hedge mode, where a contract may hold a long and
+// a short position, the first one is returned; filter by side with
+// GetPositions instead.
+func (api *PositionAPI) GetOpenPositionBySymbol(symbol string) (*PositionResponse, error) {
+	positions, err := api.GetPositions(PositionStatusOpen, PositionQueryParams{Symbol: symbol})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, position := range positions {
+		if strings.EqualFold(position.ContractPair, symbol) {
+			return &position, nil
+		}
+	}
+	return nil, nil
+}
+
 // GetPosition retrieves details for a specific position with structured response
 func (api *PositionAPI) GetPosition(positionID string) (*PositionResponse, error) {
 	endpoint := "/v1/positions"
@@ -114,6 +148,119 @@ func (api *PositionAPI) GetPosition(positionID string) (*PositionResponse, error
 	return &resultArray[0], nil
 }
 
+// ClosePosition closes a single open position at market with an order for
+// its full quantity on the opposite side. The order is reduce-only in
+// one-way mode; in hedge mode it targets the position's side instead.
+func (api *PositionAPI) ClosePosition(positionID string) (OrderResponse, error) {
+	position, err := api.GetPosition(positionID)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if position.PositionStatus != "" && position.PositionStatus != string(PositionStatusOpen) {
+		return OrderResponse{}, fmt.Errorf("position %s is not open (status %s)", positionID, position.PositionStatus)
+	}
+
+	params, err := api.closeOrder(*position, math.Abs(position.Quantity))
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	return api.client.Order.PlaceOrder(params)
+}
+
+// ClosePartial closes part of an open position at market. quantity is
+// rounded down to the contract's quantity precision and must be at least
+// the market minimum quantity, and must leave either nothing or at least
+// the minimum open.
+func (api *PositionAPI) ClosePartial(positionID string, quantity float64) (OrderResponse, error) {
+	position, err := api.GetPosition(positionID)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	params, err := api.partialCloseOrder(*position, quantity)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	return api.client.Order.PlaceOrder(params)
+}
+
+// ClosePartialFraction closes a fraction of an open position at market,
+// e.g. 0.5 for half, with the rounding and validation of ClosePartial
+func (api *PositionAPI) ClosePartialFraction(positionID string, fraction float64) (OrderResponse, error) {
+	if fraction <= 0 || fraction > 1 {
+		return OrderResponse{}, fmt.Errorf("fraction %v must be in (0, 1]", fraction)
+	}
+
+	position, err := api.GetPosition(positionID)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	params, err := api.partialCloseOrder(*position, math.Abs(position.Quantity)*fraction)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	return api.client.Order.PlaceOrder(params)
+}
+
+// partialCloseOrder builds a market order reducing a position by quantity,
+// rounded and validated against the contract's quantity filters
+func (api *PositionAPI) partialCloseOrder(position PositionResponse, quantity float64) (PlaceOrderParams, error) {
+	if position.PositionStatus != "" && position.PositionStatus != string(PositionStatusOpen) {
+		return PlaceOrderParams{}, fmt.Errorf("position %s is not open (status %s)", position.PositionID, position.PositionStatus)
+	}
+	contractInfo, err := api.client.GetContractInfo(position.ContractPair)
+	if err != nil {
+		return PlaceOrderParams{}, err
+	}
+
+	size := math.Abs(position.Quantity)
+	minQuantity := contractInfo.MarketMinQuantity
+	if minQuantity <= 0 {
+		minQuantity = contractInfo.MinQuantity
+	}
+
+	// Round down so the order never exceeds the requested quantity
+	multiplier := math.Pow10(contractInfo.QuantityPrecision)
+	quantity = math.Floor(quantity*multiplier+1e-9) / multiplier
+	if quantity >= size {
+		return api.closeOrder(position, size)
+	}
+
+	if quantity <= 0 || quantity < minQuantity {
+		return PlaceOrderParams{}, fmt.Errorf("quantity %v is below the minimum %v for %s",
+			quantity, minQuantity, position.ContractPair)
+	}
+	if remaining := roundToDecimal(size-quantity, contractInfo.QuantityPrecision); remaining < minQuantity {
+		return PlaceOrderParams{}, fmt.Errorf("closing %v would leave %v, below the minimum %v for %s; close the whole position instead",
+			quantity, remaining, minQuantity, position.ContractPair)
+	}
+	return api.closeOrder(position, quantity)
+}
+
+// closeOrder builds a market order reducing a position by quantity. It
+// fails if the account's position mode is unknown and cannot be queried,
+// since the order's fields depend on it.
+func (api *PositionAPI) closeOrder(position PositionResponse, quantity float64) (PlaceOrderParams, error) {
+	mode, err := api.client.Exchange.positionMode()
+	if err != nil {
+		return PlaceOrderParams{}, err
+	}
+
+	params := PlaceOrderParams{
+		Symbol:      position.ContractPair,
+		Side:        position.CloseSide(),
+		Type:        OrderTypeMarket,
+		Quantity:    quantity,
+		MarginAsset: position.MarginAsset,
+		ReduceOnly:  true,
+		PositionID:  position.PositionID,
+	}
+	if mode == PositionModeHedge {
+		params.ReduceOnly = false
+		params.PositionSide = position.Side()
+	}
+	return params, nil
+}
+
 // CloseAllPositions closes all open positions with structured response
 func (api *PositionAPI) CloseAllPositions() (*PositionCloseResponse, error) {
 	endpoint := "/v1/positions/close-all-positions"
@@ -0,0 +1,129 @@
+package pi42
+
+import (
+	"strings"
+	"sync"
+)
+
+// PositionCache keeps the account's open positions in memory. It is seeded
+// from GetPositions and kept current by the position events of the user data
+// stream, so strategies can look up positions without querying REST.
+type PositionCache struct {
+	client *Client
+
+	// Open positions keyed by position ID
+	positions map[string]PositionResponse
+	// Callbacks invoked whenever a position is opened, updated or closed
+	callbacks []func(event EventName, position PositionResponse)
+	// Whether the cache is registered with the user stream
+	started bool
+	// Mutex for thread-safe access to positions and callbacks
+	mu sync.RWMutex
+}
+
+// NewPositionCache creates a position cache fed by the client's user stream
+func NewPositionCache(client *Client) *PositionCache {
+	return &PositionCache{
+		client:    client,
+		positions: make(map[string]PositionResponse),
+	}
+}
+
+// Start registers the cache with the user stream and loads the open
+// positions from the REST API. The user stream must be connected separately
+// with Connect.
+func (pc *PositionCache) Start() error {
+	pc.mu.Lock()
+	started := pc.started
+	pc.started = true
+	pc.mu.Unlock()
+
+	if !started {
+		pc.client.UserStream.OnPositionUpdate(func(e UserEvent, position *PositionUpdateEvent) {
+			pc.apply(e.Event, position.PositionResponse)
+		})
+	}
+	return pc.Refresh()
+}
+
+// Refresh replaces the cached positions with the open positions from the REST API
+func (pc *PositionCache) Refresh() error {
+	positions, err := pc.client.Position.GetPositions(PositionStatusOpen, PositionQueryParams{})
+	if err != nil {
+		return err
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.positions = make(map[string]PositionResponse, len(positions))
+	for _, position := range positions {
+		pc.positions[position.PositionID] = position
+	}
+	return nil
+}
+
+// OnChange registers a callback invoked with the event name and position
+// whenever a position is opened, updated or closed
+func (pc *PositionCache) OnChange(callback func(event EventName, position PositionResponse)) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.callbacks = append(pc.callbacks, callback)
+}
+
+// Get returns an open position by ID
+func (pc *PositionCache) Get(positionID string) (PositionResponse, bool) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	position, ok := pc.positions[positionID]
+	return position, ok
+}
+
+// BySymbol returns the open positions for a contract pair
+func (pc *PositionCache) BySymbol(symbol string) []PositionResponse {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	var positions []PositionResponse
+	for _, position := range pc.positions {
+		if strings.EqualFold(position.ContractPair, symbol) {
+			positions = append(positions, position)
+		}
+	}
+	return positions
+}
+
+// All returns a snapshot of all open positions
+func (pc *PositionCache) All() []PositionResponse {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	positions := make([]PositionResponse, 0, len(pc.positions))
+	for _, position := range pc.positions {
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+// apply updates the cache from a position event and notifies callbacks
+func (pc *PositionCache) apply(event EventName, position PositionResponse) {
+	if position.PositionID == "" {
+		return
+	}
+
+	pc.mu.Lock()
+	if event == UserEventClosePosition || position.PositionStatus == string(PositionStatusClosed) {
+		delete(pc.positions, position.PositionID)
+	} else {
+		pc.positions[position.PositionID] = position
+	}
+	callbacks := append([]func(EventName, PositionResponse){}, pc.callbacks...)
+	pc.mu.Unlock()
+
+	// Invoke callbacks outside the lock so they may query the cache
+	for _, callback := range callbacks {
+		callback(event, position)
+	}
+}
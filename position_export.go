@@ -0,0 +1,168 @@
+package pi42
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PositionRecord summarizes a closed position for performance tracking and
+// accounting. Prices and amounts are in the quote asset.
+type PositionRecord struct {
+	PositionID  string        `json:"positionId"`
+	Symbol      string        `json:"symbol"`
+	Side        PositionSide  `json:"side"`
+	Quantity    float64       `json:"quantity"`
+	Leverage    int           `json:"leverage"`
+	MarginAsset string        `json:"marginAsset"`
+	EntryPrice  float64       `json:"entryPrice"`
+	ExitPrice   float64       `json:"exitPrice"` // Average price of the closing trades; 0 if none were found
+	RealizedPnl float64       `json:"realizedPnl"`
+	Fees        float64       `json:"fees"`   // Fees of the trades that opened and closed the position
+	NetPnl      float64       `json:"netPnl"` // Realized PnL less fees
+	OpenedAt    time.Time     `json:"openedAt"`
+	ClosedAt    time.Time     `json:"closedAt"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// NewPositionRecord builds the record of a closed position. trades are the
+// account's trades on the position's contract while it was open; trades
+// outside that window are ignored.
+func NewPositionRecord(position PositionResponse, trades []TradeHistoryItem) PositionRecord {
+	record := PositionRecord{
+		PositionID:  position.PositionID,
+		Symbol:      position.ContractPair,
+		Side:        position.Side(),
+		Quantity:    math.Abs(position.Quantity),
+		Leverage:    position.Leverage,
+		MarginAsset: position.MarginAsset,
+		EntryPrice:  position.EntryPrice,
+	}
+	if position.RealizedProfit != nil {
+		record.RealizedPnl = *position.RealizedProfit
+	}
+	record.OpenedAt, _ = position.ParsedCreatedTime()
+	record.ClosedAt, _ = position.ParsedUpdatedTime()
+	if !record.OpenedAt.IsZero() && record.ClosedAt.After(record.OpenedAt) {
+		record.Duration = record.ClosedAt.Sub(record.OpenedAt)
+	}
+
+	closeSide := string(position.CloseSide())
+	var exitValue, exitQuantity float64
+	for _, trade := range trades {
+		if !strings.EqualFold(trade.Symbol, position.ContractPair) {
+			continue
+		}
+		if traded, err := trade.ParsedTime(); err == nil && !record.OpenedAt.IsZero() {
+			if traded.Before(record.OpenedAt) || (!record.ClosedAt.IsZero() && traded.After(record.ClosedAt)) {
+				continue
+			}
+		}
+
+		record.Fees += trade.Fee
+		if strings.EqualFold(trade.Side, closeSide) {
+			exitValue += trade.Price * trade.Quantity
+			exitQuantity += trade.Quantity
+		}
+	}
+	if exitQuantity > 0 {
+		record.ExitPrice = exitValue / exitQuantity
+	}
+	record.NetPnl = record.RealizedPnl - record.Fees
+	return record
+}
+
+// ExportClosedPositions builds records for every closed position matching
+// the filter, fetching the trades of each position to derive its exit price
+// and fees. Requests are spaced by the filter's Interval.
+func (api *PositionAPI) ExportClosedPositions(ctx context.Context, filter ClosedPositionFilter) ([]PositionRecord, error) {
+	interval := filter.Interval
+	if interval <= 0 {
+		interval = defaultClosedPositionInterval
+	}
+
+	var records []PositionRecord
+	for position, err := range api.IterateClosedPositions(ctx, filter) {
+		if err != nil {
+			return records, err
+		}
+
+		params := DataQueryParams{Symbol: position.ContractPair, PageSize: 500}
+		if opened, err := position.ParsedCreatedTime(); err == nil {
+			params.StartTimestamp = opened.UnixMilli()
+		}
+		if closed, err := position.ParsedUpdatedTime(); err == nil {
+			params.EndTimestamp = closed.UnixMilli()
+		}
+		trades, err := api.client.UserData.tradeHistoryRange(params)
+		if err != nil {
+			return records, fmt.Errorf("error fetching trades of position %s: %v", position.PositionID, err)
+		}
+		records = append(records, NewPositionRecord(position, trades))
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return records, ctx.Err()
+		}
+	}
+	return records, nil
+}
+
+// positionCSVHeader is the header row written by WritePositionsCSV
+var positionCSVHeader = []string{
+	"positionId", "symbol", "side", "quantity", "leverage", "marginAsset",
+	"entryPrice", "exitPrice", "realizedPnl", "fees", "netPnl",
+	"openedAt", "closedAt", "durationSeconds",
+}
+
+// WritePositionsCSV writes records as CSV with a header row. Times are
+// RFC 3339 in UTC.
+func WritePositionsCSV(w io.Writer, records []PositionRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(positionCSVHeader); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+
+	formatFloat := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	for _, r := range records {
+		row := []string{
+			r.PositionID, r.Symbol, string(r.Side), formatFloat(r.Quantity), strconv.Itoa(r.Leverage), r.MarginAsset,
+			formatFloat(r.EntryPrice), formatFloat(r.ExitPrice), formatFloat(r.RealizedPnl), formatFloat(r.Fees), formatFloat(r.NetPnl),
+			formatTime(r.OpenedAt), formatTime(r.ClosedAt), formatFloat(r.Duration.Seconds()),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing CSV: %v", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+	return nil
+}
+
+// WritePositionsJSON writes records as an indented JSON array. Durations
+// are in nanoseconds.
+func WritePositionsJSON(w io.Writer, records []PositionRecord) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	return nil
+}
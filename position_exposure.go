@@ -0,0 +1,105 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// AssetExposure aggregates the open positions on one base asset. Notional
+// values are in the summary's reference asset, at entry prices.
+type AssetExposure struct {
+	Asset       string
+	Positions   int
+	NetQuantity float64 // Long minus short quantity in the base asset
+	Long        float64
+	Short       float64
+	Net         float64 // Long minus short
+	Gross       float64 // Long plus short
+}
+
+// ExposureSummary aggregates the account's open positions. Notional values
+// and margin are converted to ReferenceAsset with the exchange conversion
+// rates and valued at entry prices.
+type ExposureSummary struct {
+	ReferenceAsset string
+	// Exposure per base asset, sorted by descending gross exposure
+	Assets     []AssetExposure
+	Long       float64
+	Short      float64
+	Net        float64
+	Gross      float64
+	MarginUsed float64
+	// Average leverage of the positions weighted by notional
+	WeightedLeverage float64
+}
+
+// GetExposureSummary aggregates the open positions into long, short, net and
+// gross exposure per base asset and in total, valued in referenceAsset
+func (api *PositionAPI) GetExposureSummary(referenceAsset string) (*ExposureSummary, error) {
+	positions, err := api.GetPositions(PositionStatusOpen, PositionQueryParams{})
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeExposure(positions, api.client.Converter(), referenceAsset)
+}
+
+// SummarizeExposure aggregates positions into an exposure summary valued in
+// referenceAsset, e.g. for positions from a PositionCache
+func SummarizeExposure(positions []PositionResponse, converter *Converter, referenceAsset string) (*ExposureSummary, error) {
+	summary := &ExposureSummary{ReferenceAsset: strings.ToUpper(referenceAsset)}
+	assets := make(map[string]*AssetExposure)
+	var leverageWeight float64
+
+	for _, position := range positions {
+		rate, err := converter.Rate(position.QuoteAsset, summary.ReferenceAsset)
+		if err != nil {
+			return nil, fmt.Errorf("error valuing position %s: %v", position.PositionID, err)
+		}
+
+		notional := position.Notional(position.EntryPrice) * rate
+		quantity := math.Abs(position.Quantity)
+
+		exposure, ok := assets[position.BaseAsset]
+		if !ok {
+			exposure = &AssetExposure{Asset: position.BaseAsset}
+			assets[position.BaseAsset] = exposure
+		}
+		exposure.Positions++
+		if position.direction() < 0 {
+			exposure.Short += notional
+			exposure.NetQuantity -= quantity
+			summary.Short += notional
+		} else {
+			exposure.Long += notional
+			exposure.NetQuantity += quantity
+			summary.Long += notional
+		}
+
+		summary.MarginUsed += position.Margin * rate
+		leverageWeight += notional * float64(position.Leverage)
+	}
+
+	for _, exposure := range assets {
+		exposure.Net = exposure.Long - exposure.Short
+		exposure.Gross = exposure.Long + exposure.Short
+		summary.Assets = append(summary.Assets, *exposure)
+	}
+	slices.SortFunc(summary.Assets, func(a, b AssetExposure) int {
+		if a.Gross != b.Gross {
+			if a.Gross > b.Gross {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Asset, b.Asset)
+	})
+
+	summary.Net = summary.Long - summary.Short
+	summary.Gross = summary.Long + summary.Short
+	if summary.Gross > 0 {
+		summary.WeightedLeverage = leverageWeight / summary.Gross
+	}
+	return summary, nil
+}
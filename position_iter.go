@@ -0,0 +1,126 @@
+package pi42
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Defaults for IterateClosedPositions
+const (
+	defaultClosedPositionPageSize = 100
+	defaultClosedPositionInterval = 250 * time.Millisecond
+)
+
+// ClosedPositionFilter selects the closed positions returned by
+// IterateClosedPositions
+type ClosedPositionFilter struct {
+	StartTimestamp int64  // Milliseconds; 0 starts from the earliest position
+	EndTimestamp   int64  // Milliseconds; 0 means now
+	Symbol         string // Optional contract pair
+	// Positions requested per page; defaults to 100
+	PageSize int
+	// Minimum delay between page requests to stay within rate limits;
+	// defaults to 250ms
+	Interval time.Duration
+}
+
+// IterateClosedPositions returns an iterator over every CLOSED position
+// matching the filter, oldest first, fetching pages as needed. Iteration
+// stops after the first error, which is yielded with a zero position, or
+// when ctx is cancelled.
+//
+//	for position, err := range client.Position.IterateClosedPositions(ctx, filter) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (api *PositionAPI) IterateClosedPositions(ctx context.Context, filter ClosedPositionFilter) iter.Seq2[PositionResponse, error] {
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultClosedPositionPageSize
+	}
+	interval := filter.Interval
+	if interval <= 0 {
+		interval = defaultClosedPositionInterval
+	}
+
+	return func(yield func(PositionResponse, error) bool) {
+		end := filter.EndTimestamp
+		if end == 0 {
+			end = time.Now().UnixMilli()
+		}
+		cursor := filter.StartTimestamp
+		// Positions already yielded at the cursor timestamp, since pages
+		// overlap at their boundary
+		seen := make(map[string]bool)
+
+		for first := true; cursor <= end; first = false {
+			if !first {
+				timer := time.NewTimer(interval)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					yield(PositionResponse{}, ctx.Err())
+					return
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				yield(PositionResponse{}, err)
+				return
+			}
+
+			page, err := api.GetPositions(PositionStatusClosed, PositionQueryParams{
+				StartTimestamp: cursor,
+				EndTimestamp:   end,
+				SortOrder:      "ASC",
+				PageSize:       pageSize,
+				Symbol:         filter.Symbol,
+			})
+			if err != nil {
+				yield(PositionResponse{}, err)
+				return
+			}
+
+			next := cursor
+			added := 0
+			for _, position := range page {
+				if seen[position.PositionID] {
+					continue
+				}
+				timestamp := positionTimestamp(position)
+				if timestamp > next {
+					next = timestamp
+					clear(seen)
+				}
+				seen[position.PositionID] = true
+				added++
+
+				if !yield(position, nil) {
+					return
+				}
+			}
+
+			if len(page) < pageSize {
+				return
+			}
+			// A full page sharing one timestamp would repeat forever
+			if added == 0 {
+				next++
+			}
+			cursor = next
+		}
+	}
+}
+
+// positionTimestamp returns the time a position was last updated in
+// milliseconds, or 0 if it cannot be parsed
+func positionTimestamp(p PositionResponse) int64 {
+	t, err := p.ParsedUpdatedTime()
+	if err != nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,98 @@
+package pi42
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PositionMode selects whether a contract holds one net position or
+// separate long and short positions
+type PositionMode string
+
+// Supported position modes
+const (
+	// PositionModeOneWay holds a single position per contract
+	PositionModeOneWay PositionMode = "ONE_WAY"
+	// PositionModeHedge holds a long and a short position per contract at
+	// the same time; orders select one with PlaceOrderParams.PositionSide
+	PositionModeHedge PositionMode = "HEDGE"
+)
+
+// PositionModeResponse represents the account's position mode
+type PositionModeResponse struct {
+	PositionMode PositionMode `json:"positionMode"`
+}
+
+// GetPositionMode retrieves the account's position mode
+func (api *ExchangeAPI) GetPositionMode() (PositionMode, error) {
+	endpoint := "/v1/exchange/position-mode"
+
+	data, err := api.client.Get(endpoint, nil, false)
+	if err != nil {
+		return "", err
+	}
+
+	var result PositionModeResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("error parsing response: %v", err)
+	}
+
+	api.setCachedPositionMode(result.PositionMode)
+	return result.PositionMode, nil
+}
+
+// SetPositionMode switches the account between one-way and hedge mode. The
+// exchange refuses the change while positions or orders are open.
+func (api *ExchangeAPI) SetPositionMode(mode PositionMode) (PositionMode, error) {
+	if mode != PositionModeOneWay && mode != PositionModeHedge {
+		return "", fmt.Errorf("invalid position mode %q (expected %s or %s)", mode, PositionModeOneWay, PositionModeHedge)
+	}
+
+	endpoint := "/v1/exchange/update/position-mode"
+
+	params := map[string]interface{}{
+		"positionMode": mode,
+	}
+
+	data, err := api.client.Post(endpoint, params, false)
+	if err != nil {
+		return "", err
+	}
+
+	var result PositionModeResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("error parsing response: %v", err)
+	}
+	if result.PositionMode == "" {
+		result.PositionMode = mode
+	}
+
+	api.setCachedPositionMode(result.PositionMode)
+	return result.PositionMode, nil
+}
+
+// positionMode returns the last known position mode, querying it if
+// unknown. Orders that depend on the mode must not guess it, so a failed
+// query is returned as an error.
+func (api *ExchangeAPI) positionMode() (PositionMode, error) {
+	api.mu.Lock()
+	mode := api.cachedPositionMode
+	api.mu.Unlock()
+	if mode != "" {
+		return mode, nil
+	}
+
+	mode, err := api.GetPositionMode()
+	if err != nil {
+		return "", fmt.Errorf("error determining position mode: %v", err)
+	}
+	return mode, nil
+}
+
+// setCachedPositionMode records the account's position mode
+func (api *ExchangeAPI) setCachedPositionMode(mode PositionMode) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.cachedPositionMode = mode
+}
@@ -0,0 +1,77 @@
+package pi42
+
+import "math"
+
+// direction returns 1 for long positions and -1 for short positions
+func (p PositionResponse) direction() float64 {
+	if p.Side() == PositionSideShort {
+		return -1
+	}
+	return 1
+}
+
+// Notional returns the position's value in the quote asset at a price
+func (p PositionResponse) Notional(price float64) float64 {
+	return math.Abs(p.Quantity) * price
+}
+
+// InitialMargin returns the margin required to open the position at its
+// entry price and leverage, in the quote asset. It falls back to the
+// position's reported margin if the leverage is unknown.
+func (p PositionResponse) InitialMargin() float64 {
+	if p.Leverage <= 0 {
+		return p.Margin
+	}
+	return p.Notional(p.EntryPrice) / float64(p.Leverage)
+}
+
+// UnrealizedPnl returns the profit or loss of the position at a mark price,
+// in the quote asset, before fees and funding
+func (p PositionResponse) UnrealizedPnl(markPrice float64) float64 {
+	return (markPrice - p.EntryPrice) * math.Abs(p.Quantity) * p.direction()
+}
+
+// ROE returns the return on the position's initial margin at a mark price,
+// as a percentage
+func (p PositionResponse) ROE(markPrice float64) float64 {
+	margin := p.InitialMargin()
+	if margin == 0 {
+		return 0
+	}
+	return p.UnrealizedPnl(markPrice) / margin * 100
+}
+
+// UnrealizedPnlInMarginAsset returns the unrealized PnL at a mark price
+// converted to the margin asset, using the rate implied by the position's
+// margin in both assets. It returns false if the rate is unknown.
+func (p PositionResponse) UnrealizedPnlInMarginAsset(markPrice float64) (float64, bool) {
+	pnl := p.UnrealizedPnl(markPrice)
+	if p.MarginAsset == "" || p.MarginAsset == p.QuoteAsset {
+		return pnl, true
+	}
+	if p.Margin == 0 || p.MarginInMarginAsset == 0 {
+		return 0, false
+	}
+	return pnl * p.MarginInMarginAsset / p.Margin, true
+}
+
+// BreakEvenPrice returns the exit price at which closing the position nets
+// zero after fees and funding. Fee rates are fractions of the notional
+// (e.g. 0.0005 for 0.05%), for the trade that opened the position and the
+// one that will close it; use the taker or maker rate as appropriate.
+// fundingPaid is the net funding paid by the position so far in the quote
+// asset, negative if funding was received. It returns 0 for an empty
+// position.
+func (p PositionResponse) BreakEvenPrice(entryFeeRate, exitFeeRate, fundingPaid float64) float64 {
+	quantity := math.Abs(p.Quantity)
+	if quantity == 0 {
+		return 0
+	}
+
+	// Solve (exit - entry) * quantity * direction = entry fee + exit fee + funding
+	entryCost := p.EntryPrice * quantity * entryFeeRate
+	if p.Side() == PositionSideShort {
+		return (p.EntryPrice*quantity - entryCost - fundingPaid) / (quantity * (1 + exitFeeRate))
+	}
+	return (p.EntryPrice*quantity + entryCost + fundingPaid) / (quantity * (1 - exitFeeRate))
+}
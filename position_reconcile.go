@@ -0,0 +1,213 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// reconcileTolerance is the quantity difference below which positions and
+// trades are considered to match
+const reconcileTolerance = 1e-8
+
+// MismatchKind classifies a discrepancy found by PositionReconciler
+type MismatchKind string
+
+// Mismatch kinds
+const (
+	// MismatchUnknownPosition is an open position on the exchange that the
+	// local state does not know about
+	MismatchUnknownPosition MismatchKind = "UNKNOWN_POSITION"
+	// MismatchMissingPosition is a local position that is no longer open on
+	// the exchange
+	MismatchMissingPosition MismatchKind = "MISSING_POSITION"
+	// MismatchQuantity is a position whose local quantity differs from the exchange
+	MismatchQuantity MismatchKind = "QUANTITY"
+	// MismatchTrades is an open position whose quantity does not match the
+	// net quantity traded on its contract since it was opened
+	MismatchTrades MismatchKind = "TRADES"
+	// MismatchOrphanOrder is an open reduce-only order on a contract without
+	// an open position
+	MismatchOrphanOrder MismatchKind = "ORPHAN_ORDER"
+)
+
+// Mismatch is a discrepancy between local state and the exchange
+type Mismatch struct {
+	Kind          MismatchKind
+	Symbol        string
+	PositionID    string // Empty for orphan orders
+	ClientOrderID string // Set for orphan orders
+	Local         float64
+	Exchange      float64
+	Detail        string
+}
+
+// ReconcileReport lists the mismatches found by a reconciliation
+type ReconcileReport struct {
+	Time       time.Time
+	Mismatches []Mismatch
+}
+
+// OK reports whether no mismatches were found
+func (r ReconcileReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// PositionReconciler cross-checks positions tracked locally, e.g. by a bot
+// that restarted after a crash, against the exchange's open positions,
+// trade history and order history
+type PositionReconciler struct {
+	client *Client
+}
+
+// NewPositionReconciler creates a reconciler using the client's REST API
+func NewPositionReconciler(client *Client) *PositionReconciler {
+	return &PositionReconciler{client: client}
+}
+
+// Reconcile compares local positions with the exchange and returns the
+// mismatches found. Open positions are matched by position ID.
+func (r *PositionReconciler) Reconcile(local []PositionResponse) (*ReconcileReport, error) {
+	open, err := r.client.Position.GetPositions(PositionStatusOpen, PositionQueryParams{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching open positions: %v", err)
+	}
+	orders, err := r.client.Order.GetOpenOrders(OrderQueryParams{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching open orders: %v", err)
+	}
+
+	report := &ReconcileReport{Time: time.Now()}
+	exchange := make(map[string]PositionResponse, len(open))
+	openSymbols := make(map[string]bool)
+	for _, position := range open {
+		exchange[position.PositionID] = position
+		openSymbols[strings.ToUpper(position.ContractPair)] = true
+	}
+	known := make(map[string]bool, len(local))
+
+	for _, position := range local {
+		known[position.PositionID] = true
+		current, ok := exchange[position.PositionID]
+		if !ok {
+			report.Mismatches = append(report.Mismatches, Mismatch{
+				Kind:       MismatchMissingPosition,
+				Symbol:     position.ContractPair,
+				PositionID: position.PositionID,
+				Local:      math.Abs(position.Quantity),
+				Detail:     r.closingDetail(position),
+			})
+			continue
+		}
+		if math.Abs(math.Abs(position.Quantity)-math.Abs(current.Quantity)) > reconcileTolerance {
+			report.Mismatches = append(report.Mismatches, Mismatch{
+				Kind:       MismatchQuantity,
+				Symbol:     position.ContractPair,
+				PositionID: position.PositionID,
+				Local:      math.Abs(position.Quantity),
+				Exchange:   math.Abs(current.Quantity),
+			})
+		}
+	}
+
+	for _, position := range open {
+		if !known[position.PositionID] {
+			report.Mismatches = append(report.Mismatches, Mismatch{
+				Kind:       MismatchUnknownPosition,
+				Symbol:     position.ContractPair,
+				PositionID: position.PositionID,
+				Exchange:   math.Abs(position.Quantity),
+			})
+		}
+
+		mismatch, err := r.checkTrades(position)
+		if err != nil {
+			return nil, err
+		}
+		if mismatch != nil {
+			report.Mismatches = append(report.Mismatches, *mismatch)
+		}
+	}
+
+	for _, order := range orders {
+		if order.ReduceOnly && !openSymbols[strings.ToUpper(order.Symbol)] {
+			report.Mismatches = append(report.Mismatches, Mismatch{
+				Kind:          MismatchOrphanOrder,
+				Symbol:        order.Symbol,
+				ClientOrderID: order.ClientOrderID,
+				Local:         order.OrderAmount - order.FilledAmount,
+				Detail:        "reduce-only order without an open position",
+			})
+		}
+	}
+	return report, nil
+}
+
+// checkTrades compares an open position's quantity with the net quantity
+// traded on its contract since it was opened
+func (r *PositionReconciler) checkTrades(position PositionResponse) (*Mismatch, error) {
+	opened, err := position.ParsedCreatedTime()
+	if err != nil {
+		return nil, nil
+	}
+
+	trades, err := r.client.UserData.tradeHistoryRange(DataQueryParams{
+		StartTimestamp: opened.UnixMilli(),
+		Symbol:         position.ContractPair,
+		PageSize:       500,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching trades for %s: %v", position.ContractPair, err)
+	}
+
+	var net float64
+	for _, trade := range trades {
+		if !strings.EqualFold(trade.Symbol, position.ContractPair) {
+			continue
+		}
+		if strings.EqualFold(trade.Side, string(OrderSideBuy)) {
+			net += trade.Quantity
+		} else {
+			net -= trade.Quantity
+		}
+	}
+
+	traded := net * position.direction()
+	if math.Abs(traded-math.Abs(position.Quantity)) <= reconcileTolerance {
+		return nil, nil
+	}
+	return &Mismatch{
+		Kind:       MismatchTrades,
+		Symbol:     position.ContractPair,
+		PositionID: position.PositionID,
+		Local:      traded,
+		Exchange:   math.Abs(position.Quantity),
+		Detail:     fmt.Sprintf("%d trades since %s", len(trades), opened.Format(time.RFC3339)),
+	}, nil
+}
+
+// closingDetail describes the filled orders on a missing position's
+// contract since it was last updated, which likely closed it
+func (r *PositionReconciler) closingDetail(position PositionResponse) string {
+	params := OrderQueryParams{Symbol: position.ContractPair, PageSize: 50}
+	if updated, err := position.ParsedUpdatedTime(); err == nil {
+		params.StartTimestamp = updated.UnixMilli()
+	}
+	history, err := r.client.Order.GetOrderHistory(params)
+	if err != nil {
+		return fmt.Sprintf("order history unavailable: %v", err)
+	}
+
+	closeSide := string(position.CloseSide())
+	var closing []string
+	for _, order := range history {
+		if strings.EqualFold(order.Side, closeSide) && order.Status == string(OrderStatusFilled) {
+			closing = append(closing, order.ClientOrderID)
+		}
+	}
+	if len(closing) == 0 {
+		return "no closing order found in order history"
+	}
+	return "closed by " + strings.Join(closing, ", ")
+}
@@ -0,0 +1,77 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+)
+
+// RiskReport describes how close a position is to liquidation at a mark
+// price. Amounts are in the quote asset.
+type RiskReport struct {
+	PositionID    string
+	Symbol        string
+	MarkPrice     float64
+	Notional      float64 // Position value at the mark price
+	Margin        float64
+	UnrealizedPnl float64
+	// Maintenance margin rate of the position's tier, in percent
+	MaintMarginPercent float64
+	// Margin the position must keep to avoid liquidation
+	MaintenanceMargin float64
+	// Maintenance margin as a percentage of the margin balance (margin plus
+	// unrealized PnL). The position is liquidated when it reaches 100.
+	MarginRatio float64
+	// Price move from the mark price to the liquidation price, in price
+	// and in percent of the mark price. Zero if the liquidation price is unknown.
+	LiquidationPrice         float64
+	LiquidationDistance      float64
+	LiquidationDistancePrice float64
+}
+
+// RiskReport computes the risk metrics of a position at a mark price. The
+// maintenance margin rate comes from the contract's margin tiers for the
+// position's notional, falling back to the rate reported on the position
+// and then to the contract's default rate.
+func (c *Client) RiskReport(position PositionResponse, markPrice float64) (RiskReport, error) {
+	if markPrice <= 0 {
+		return RiskReport{}, fmt.Errorf("invalid mark price %v for %s", markPrice, position.ContractPair)
+	}
+
+	report := RiskReport{
+		PositionID:       position.PositionID,
+		Symbol:           position.ContractPair,
+		MarkPrice:        markPrice,
+		Notional:         position.Notional(markPrice),
+		Margin:           position.Margin,
+		UnrealizedPnl:    position.UnrealizedPnl(markPrice),
+		LiquidationPrice: position.LiquidationPrice,
+	}
+
+	// An unknown contract falls back to the position's own rate below
+	contract, _ := c.GetContractInfo(position.ContractPair)
+
+	if tier, ok := contract.LookupTier(report.Notional); ok {
+		report.MaintMarginPercent = tier.MaintMarginPercent
+		report.MaintenanceMargin = tier.MaintenanceMargin(report.Notional)
+	} else if position.MaintenanceMarginPercentage != nil {
+		report.MaintMarginPercent = *position.MaintenanceMarginPercentage
+		report.MaintenanceMargin = report.Notional * report.MaintMarginPercent / 100
+	} else if contract.MaintenanceMarginPercentage > 0 {
+		report.MaintMarginPercent = contract.MaintenanceMarginPercentage
+		report.MaintenanceMargin = report.Notional * report.MaintMarginPercent / 100
+	} else {
+		return RiskReport{}, fmt.Errorf("no maintenance margin rate known for %s", position.ContractPair)
+	}
+
+	if balance := report.Margin + report.UnrealizedPnl; balance > 0 {
+		report.MarginRatio = report.MaintenanceMargin / balance * 100
+	} else {
+		report.MarginRatio = math.Inf(1)
+	}
+
+	if distance, ok := LiquidationDistance(position, markPrice); ok {
+		report.LiquidationDistance = distance
+		report.LiquidationDistancePrice = math.Abs(markPrice - position.LiquidationPrice)
+	}
+	return report, nil
+}
@@ -0,0 +1,183 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// TrailingStopConfig configures a position's trailing stop. Exactly one of
+// Distance and DistancePercent must be set.
+type TrailingStopConfig struct {
+	// Retracement from the best mark price that closes the position, in price
+	Distance float64
+	// Retracement from the best mark price that closes the position, in
+	// percent of the best price
+	DistancePercent float64
+	// Optional mark price the position must reach before the stop starts
+	// trailing; 0 trails immediately
+	ActivationPrice float64
+}
+
+// TrailingStop follows the mark price of one position and closes it with a
+// reduce-only market order once the price retraces from its best level by
+// the configured distance. The position is read from a PositionCache, so
+// size changes are picked up and the stop keeps working across stream
+// reconnects.
+type TrailingStop struct {
+	client     *Client
+	positions  *PositionCache
+	socket     *SocketClient
+	positionID string
+	config     TrailingStopConfig
+
+	// Best mark price seen since activation, 0 until activated
+	peak float64
+	// Whether the stop triggered or was stopped; no further orders are sent
+	done      bool
+	callbacks []func(OrderResponse, error)
+	mu        sync.Mutex
+}
+
+// NewTrailingStop creates a trailing stop for an open position in the
+// cache, reading mark prices from socket. Call Start to begin trailing.
+func NewTrailingStop(client *Client, positions *PositionCache, socket *SocketClient, positionID string, config TrailingStopConfig) (*TrailingStop, error) {
+	if (config.Distance > 0) == (config.DistancePercent > 0) {
+		return nil, fmt.Errorf("trailing stop needs exactly one of Distance and DistancePercent")
+	}
+	if _, ok := positions.Get(positionID); !ok {
+		return nil, fmt.Errorf("position %s is not in the position cache", positionID)
+	}
+
+	return &TrailingStop{
+		client:     client,
+		positions:  positions,
+		socket:     socket,
+		positionID: positionID,
+		config:     config,
+	}, nil
+}
+
+// OnTrigger registers a callback invoked with the result of the close order
+func (ts *TrailingStop) OnTrigger(callback func(order OrderResponse, err error)) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.callbacks = append(ts.callbacks, callback)
+}
+
+// Start subscribes to the position's mark price and begins trailing
+func (ts *TrailingStop) Start() {
+	position, ok := ts.positions.Get(ts.positionID)
+	if !ok {
+		ts.Stop()
+		return
+	}
+	ts.socket.OnMarkPrice(position.ContractPair, ts.update)
+}
+
+// Stop disables the trailing stop without closing the position
+func (ts *TrailingStop) Stop() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.done = true
+}
+
+// Peak returns the best mark price seen since the stop activated, or 0 if
+// it has not activated yet
+func (ts *TrailingStop) Peak() float64 {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return ts.peak
+}
+
+// StopPrice returns the mark price that triggers the close, or 0 if the
+// stop has not activated yet
+func (ts *TrailingStop) StopPrice() float64 {
+	position, ok := ts.positions.Get(ts.positionID)
+	if !ok {
+		return 0
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return ts.stopPrice(position.direction())
+}
+
+// stopPrice returns the trigger price for the current peak. The caller
+// must hold mu.
+func (ts *TrailingStop) stopPrice(direction float64) float64 {
+	if ts.peak == 0 {
+		return 0
+	}
+	distance := ts.config.Distance
+	if ts.config.DistancePercent > 0 {
+		distance = ts.peak * ts.config.DistancePercent / 100
+	}
+	return ts.peak - distance*direction
+}
+
+// update processes a mark price and closes the position when the stop price is crossed
+func (ts *TrailingStop) update(event MarkPriceEvent) {
+	markPrice, err := strconv.ParseFloat(event.MarkPrice, 64)
+	if err != nil || markPrice <= 0 {
+		return
+	}
+
+	position, ok := ts.positions.Get(ts.positionID)
+
+	ts.mu.Lock()
+	if ts.done {
+		ts.mu.Unlock()
+		return
+	}
+	if !ok {
+		// The position was closed elsewhere
+		ts.done = true
+		ts.mu.Unlock()
+		return
+	}
+
+	direction := position.direction()
+	if ts.peak == 0 {
+		if ts.config.ActivationPrice > 0 && (markPrice-ts.config.ActivationPrice)*direction < 0 {
+			ts.mu.Unlock()
+			return
+		}
+		ts.peak = markPrice
+	}
+	if (markPrice-ts.peak)*direction > 0 {
+		ts.peak = markPrice
+	}
+
+	stop := ts.stopPrice(direction)
+	if (markPrice-stop)*direction > 0 {
+		ts.mu.Unlock()
+		return
+	}
+	ts.done = true
+	peak := ts.peak
+	callbacks := append([]func(OrderResponse, error){}, ts.callbacks...)
+	ts.mu.Unlock()
+
+	logger().Infof("Trailing stop for position %s triggered at %v (best %v, stop %v)", ts.positionID, markPrice, peak, stop)
+
+	// Place the order off the socket's read goroutine
+	go func() {
+		var order OrderResponse
+		params, err := ts.client.Position.closeOrder(position, math.Abs(position.Quantity))
+		if err == nil {
+			order, err = ts.client.Order.PlaceOrder(params)
+		}
+		if err != nil {
+			logger().Errorf("Trailing stop could not close position %s: %v", ts.positionID, err)
+		}
+		for _, callback := range callbacks {
+			callback(order, err)
+		}
+	}()
+}
@@ -55,6 +55,22 @@ type PositionCloseStatus struct {
 	Message    string `json:"message"`
 }
 
+// Side returns whether the position is long or short
+func (p PositionResponse) Side() PositionSide {
+	if PositionSide(p.PositionType) == PositionSideShort {
+		return PositionSideShort
+	}
+	return PositionSideLong
+}
+
+// CloseSide returns the order side that reduces the position
+func (p PositionResponse) CloseSide() OrderSide {
+	if p.Side() == PositionSideShort {
+		return OrderSideBuy
+	}
+	return OrderSideSell
+}
+
 // ParsedCreatedTime parses the CreatedTime field string into a time.Time object
 func (p PositionResponse) ParsedCreatedTime() (time.Time, error) {
 	if p.CreatedTime != "" {
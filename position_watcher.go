@@ -0,0 +1,164 @@
+package pi42
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultLiquidationThresholds are the distances to liquidation, in percent
+// of the mark price, at which PositionWatcher warns by default
+var defaultLiquidationThresholds = []float64{5, 2}
+
+// LiquidationWarning reports that a position's mark price came within a
+// threshold of its liquidation price
+type LiquidationWarning struct {
+	Position  PositionResponse
+	MarkPrice float64
+	// Distance from the mark price to the liquidation price, in percent of
+	// the mark price
+	Distance float64
+	// The threshold that was crossed, in percent
+	Threshold float64
+}
+
+// PositionWatcher tracks the mark price of every open position against its
+// liquidation price and warns when the distance falls below configured
+// thresholds. Each threshold warns once per position until the distance
+// recovers above it.
+type PositionWatcher struct {
+	positions *PositionCache
+	socket    *SocketClient
+
+	// Warning thresholds in percent, in descending order
+	thresholds []float64
+	callbacks  []func(LiquidationWarning)
+	// Number of thresholds crossed per position ID
+	levels map[string]int
+	// Symbols with a mark price subscription
+	symbols map[string]bool
+	started bool
+	mu      sync.Mutex
+}
+
+// NewPositionWatcher creates a watcher for the positions in a cache, reading
+// mark prices from socket. Thresholds are distances to liquidation in
+// percent of the mark price; they default to 5% and 2%.
+func NewPositionWatcher(positions *PositionCache, socket *SocketClient, thresholds ...float64) *PositionWatcher {
+	if len(thresholds) == 0 {
+		thresholds = defaultLiquidationThresholds
+	}
+	thresholds = slices.Clone(thresholds)
+	slices.Sort(thresholds)
+	slices.Reverse(thresholds)
+
+	return &PositionWatcher{
+		positions:  positions,
+		socket:     socket,
+		thresholds: thresholds,
+		levels:     make(map[string]int),
+		symbols:    make(map[string]bool),
+	}
+}
+
+// OnWarning registers a callback invoked when a position crosses a threshold
+func (w *PositionWatcher) OnWarning(callback func(LiquidationWarning)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, callback)
+}
+
+// Start subscribes to the mark price of every open position, and of
+// positions opened later. The position cache must be started separately.
+func (w *PositionWatcher) Start() {
+	w.mu.Lock()
+	started := w.started
+	w.started = true
+	w.mu.Unlock()
+	if started {
+		return
+	}
+
+	w.positions.OnChange(func(event EventName, position PositionResponse) {
+		if event == UserEventClosePosition || position.PositionStatus == string(PositionStatusClosed) {
+			w.mu.Lock()
+			delete(w.levels, position.PositionID)
+			w.mu.Unlock()
+			return
+		}
+		w.watch(position.ContractPair)
+	})
+	for _, position := range w.positions.All() {
+		w.watch(position.ContractPair)
+	}
+}
+
+// watch subscribes to a symbol's mark price once
+func (w *PositionWatcher) watch(symbol string) {
+	symbol = strings.ToUpper(symbol)
+
+	w.mu.Lock()
+	subscribed := w.symbols[symbol]
+	w.symbols[symbol] = true
+	w.mu.Unlock()
+
+	if !subscribed && symbol != "" {
+		w.socket.OnMarkPrice(symbol, w.check)
+	}
+}
+
+// check compares a mark price with the liquidation price of the symbol's positions
+func (w *PositionWatcher) check(event MarkPriceEvent) {
+	markPrice, err := strconv.ParseFloat(event.MarkPrice, 64)
+	if err != nil || markPrice <= 0 {
+		return
+	}
+
+	var warnings []LiquidationWarning
+	w.mu.Lock()
+	for _, position := range w.positions.BySymbol(event.Symbol) {
+		distance, ok := LiquidationDistance(position, markPrice)
+		if !ok {
+			continue
+		}
+
+		level := 0
+		for level < len(w.thresholds) && distance <= w.thresholds[level] {
+			level++
+		}
+		previous := w.levels[position.PositionID]
+		w.levels[position.PositionID] = level
+
+		// Warn for the innermost newly crossed threshold only
+		if level > previous {
+			warnings = append(warnings, LiquidationWarning{
+				Position:  position,
+				MarkPrice: markPrice,
+				Distance:  distance,
+				Threshold: w.thresholds[level-1],
+			})
+		}
+	}
+	callbacks := slices.Clone(w.callbacks)
+	w.mu.Unlock()
+
+	for _, warning := range warnings {
+		for _, callback := range callbacks {
+			callback(warning)
+		}
+	}
+}
+
+// LiquidationDistance returns how far a mark price is from a position's
+// liquidation price, in percent of the mark price. It is negative once the
+// mark price is past the liquidation price. It returns false if the
+// position has no liquidation price.
+func LiquidationDistance(position PositionResponse, markPrice float64) (float64, bool) {
+	if position.LiquidationPrice <= 0 || markPrice <= 0 {
+		return 0, false
+	}
+	distance := (markPrice - position.LiquidationPrice) / markPrice * 100
+	return distance * position.direction(), true
+}
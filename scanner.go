@@ -0,0 +1,264 @@
+package pi42
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// ScanSortField selects the metric used to order scan results
+type ScanSortField string
+
+// Supported scan sort fields
+const (
+	ScanSortVolume      ScanSortField = "VOLUME"       // 24h quote volume
+	ScanSortChange      ScanSortField = "CHANGE"       // 24h price change percent
+	ScanSortAbsChange   ScanSortField = "ABS_CHANGE"   // Absolute 24h price change percent
+	ScanSortSpread      ScanSortField = "SPREAD"       // Bid/ask spread percent
+	ScanSortFundingRate ScanSortField = "FUNDING_RATE" // Current funding rate
+)
+
+// ScanCriteria defines filters and ordering for a market scan.
+// Zero values disable the corresponding filter.
+type ScanCriteria struct {
+	QuoteAsset       string        // Only include contracts quoted in this asset (e.g., "INR")
+	MinQuoteVolume   float64       // Minimum 24h quote volume
+	MinChangePercent float64       // Minimum 24h price change percent (use negative values for losers)
+	MaxChangePercent float64       // Maximum 24h price change percent
+	MaxSpreadPercent float64       // Maximum bid/ask spread percent; requires depth requests
+	MinFundingRate   float64       // Minimum absolute funding rate; requires a funding rate source
+	SortBy           ScanSortField // Metric to sort by, defaults to volume
+	Ascending        bool          // Sort ascending instead of descending
+	Limit            int           // Maximum number of results, 0 for all
+}
+
+// ScanResult holds the metrics of a contract that matched a scan
+type ScanResult struct {
+	Symbol             string
+	LastPrice          float64
+	PriceChangePercent float64
+	Volume             float64 // 24h base asset volume
+	QuoteVolume        float64 // 24h quote asset volume
+	SpreadPercent      float64 // Bid/ask spread percent, set when spread was evaluated
+	FundingRate        float64 // Funding rate, set when a funding rate source is configured
+	HasSpread          bool
+	HasFundingRate     bool
+}
+
+// Scanner filters and ranks contracts by market activity
+type Scanner struct {
+	client *Client
+	// Optional live ticker source; tickers are fetched over REST when nil
+	tickers *TickerCache
+	// Optional funding rate lookup, e.g. fed by markPriceUpdate events
+	fundingRate func(symbol string) (float64, bool)
+}
+
+// NewScanner creates a scanner that fetches tickers over REST for every
+// contract in the client's exchange info
+func NewScanner(client *Client) *Scanner {
+	return &Scanner{client: client}
+}
+
+// UseTickerCache makes the scanner read tickers from a live cache instead of REST
+func (s *Scanner) UseTickerCache(cache *TickerCache) {
+	s.tickers = cache
+}
+
+// SetFundingRateSource sets the lookup used for funding rate filters and sorting
+func (s *Scanner) SetFundingRateSource(source func(symbol string) (float64, bool)) {
+	s.fundingRate = source
+}
+
+// Scan evaluates all contracts against the criteria and returns the matches in order
+func (s *Scanner) Scan(criteria ScanCriteria) ([]ScanResult, error) {
+	tickers, err := s.loadTickers(criteria.QuoteAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	needSpread := criteria.MaxSpreadPercent > 0 || criteria.SortBy == ScanSortSpread
+	needFunding := criteria.MinFundingRate > 0 || criteria.SortBy == ScanSortFundingRate
+	if needFunding && s.fundingRate == nil {
+		return nil, fmt.Errorf("funding rate criteria require a funding rate source")
+	}
+
+	var results []ScanResult
+	for symbol, ticker := range tickers {
+		result, err := tickerToScanResult(symbol, ticker)
+		if err != nil {
+			continue // Skip tickers with malformed values
+		}
+
+		if criteria.MinQuoteVolume > 0 && result.QuoteVolume < criteria.MinQuoteVolume {
+			continue
+		}
+		if criteria.MinChangePercent != 0 && result.PriceChangePercent < criteria.MinChangePercent {
+			continue
+		}
+		if criteria.MaxChangePercent != 0 && result.PriceChangePercent > criteria.MaxChangePercent {
+			continue
+		}
+
+		if needFunding {
+			rate, ok := s.fundingRate(symbol)
+			if !ok {
+				continue
+			}
+			result.FundingRate = rate
+			result.HasFundingRate = true
+			if criteria.MinFundingRate > 0 && math.Abs(rate) < criteria.MinFundingRate {
+				continue
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	// Spreads need a depth request each, so evaluate them only for the remaining candidates
+	if needSpread {
+		filtered := results[:0]
+		for _, result := range results {
+			spread, err := s.spreadPercent(result.Symbol)
+			if err != nil {
+				continue
+			}
+			result.SpreadPercent = spread
+			result.HasSpread = true
+			if criteria.MaxSpreadPercent > 0 && spread > criteria.MaxSpreadPercent {
+				continue
+			}
+			filtered = append(filtered, result)
+		}
+		results = filtered
+	}
+
+	sortScanResults(results, criteria.SortBy, criteria.Ascending)
+
+	if criteria.Limit > 0 && len(results) > criteria.Limit {
+		results = results[:criteria.Limit]
+	}
+	return results, nil
+}
+
+// loadTickers returns tickers for all contracts, optionally limited to a quote asset
+func (s *Scanner) loadTickers(quoteAsset string) (map[string]Ticker, error) {
+	s.client.infoMu.RLock()
+	exchangeInfo := s.client.ExchangeInfo
+	s.client.infoMu.RUnlock()
+
+	var symbols []string
+	for symbol, info := range exchangeInfo {
+		if quoteAsset == "" || info.QuoteAsset == quoteAsset {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no contracts available to scan")
+	}
+
+	if s.tickers != nil {
+		tickers := make(map[string]Ticker, len(symbols))
+		for _, symbol := range symbols {
+			if ticker, ok := s.tickers.Get(symbol); ok {
+				tickers[symbol] = ticker
+			}
+		}
+		return tickers, nil
+	}
+
+	tickers, errs := s.client.Market.GetTickers(symbols)
+	if len(errs) > 0 {
+		failed := slices.Sorted(maps.Keys(errs))
+		if len(tickers) == 0 {
+			return nil, fmt.Errorf("error fetching tickers: all %d requests failed, %s: %v",
+				len(failed), failed[0], errs[failed[0]])
+		}
+		logger().Warnf("Could not fetch tickers for %d of %d symbols (%s: %v); scanning the rest",
+			len(failed), len(symbols), failed[0], errs[failed[0]])
+	}
+	return tickers, nil
+}
+
+// spreadPercent returns the bid/ask spread as a percentage of the mid price
+func (s *Scanner) spreadPercent(symbol string) (float64, error) {
+	depth, err := s.client.Market.GetDepthLimit(symbol, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(depth.Data.Bids) == 0 || len(depth.Data.Asks) == 0 {
+		return 0, fmt.Errorf("order book for %s is empty", symbol)
+	}
+
+	bid, err := strconv.ParseFloat(depth.Data.Bids[0][0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse bid price: %v", err)
+	}
+	ask, err := strconv.ParseFloat(depth.Data.Asks[0][0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse ask price: %v", err)
+	}
+
+	mid := (bid + ask) / 2
+	if mid == 0 {
+		return 0, fmt.Errorf("order book for %s has a zero mid price", symbol)
+	}
+	return (ask - bid) / mid * 100, nil
+}
+
+// tickerToScanResult parses the numeric ticker fields used by the scanner
+func tickerToScanResult(symbol string, ticker Ticker) (ScanResult, error) {
+	result := ScanResult{Symbol: symbol}
+	fields := []struct {
+		value string
+		dest  *float64
+	}{
+		{ticker.LastPrice, &result.LastPrice},
+		{ticker.PriceChangePercent, &result.PriceChangePercent},
+		{ticker.Volume, &result.Volume},
+		{ticker.QuoteVolume, &result.QuoteVolume},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return result, err
+		}
+		*field.dest = f
+	}
+	return result, nil
+}
+
+// sortScanResults orders results by the selected metric
+func sortScanResults(results []ScanResult, field ScanSortField, ascending bool) {
+	metric := func(r ScanResult) float64 {
+		switch field {
+		case ScanSortChange:
+			return r.PriceChangePercent
+		case ScanSortAbsChange:
+			return math.Abs(r.PriceChangePercent)
+		case ScanSortSpread:
+			return r.SpreadPercent
+		case ScanSortFundingRate:
+			return r.FundingRate
+		default:
+			return r.QuoteVolume
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := metric(results[i]), metric(results[j])
+		if a == b {
+			return results[i].Symbol < results[j].Symbol
+		}
+		if ascending {
+			return a < b
+		}
+		return a > b
+	})
+}
@@ -74,7 +74,7 @@ func main() {
 		select {
 		case <-namespaceReady:
 			time.Sleep(500 * time.Millisecond)
-			subscribe := `42["subscribe",{"params":["btcinr@kline _ 1m"]}]`
+			subscribe := `42["subscribe",{"params":["btcinr@kline_1m"]}]`
 			_, err := ws.Write([]byte(subscribe))
 			if err != nil {
 				log.Println("Subscribe error:", err)
@@ -0,0 +1,144 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// candle holds the numeric values of a kline
+type candle struct {
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// parseKlines converts string-encoded klines into numeric candles
+func parseKlines(klines []KlineData) ([]candle, error) {
+	candles := make([]candle, len(klines))
+	for i, k := range klines {
+		values := []string{k.Open, k.High, k.Low, k.Close, k.Volume}
+		parsed := make([]float64, len(values))
+		for j, v := range values {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse kline %d: %v", i, err)
+			}
+			parsed[j] = f
+		}
+		candles[i] = candle{
+			Open:   parsed[0],
+			High:   parsed[1],
+			Low:    parsed[2],
+			Close:  parsed[3],
+			Volume: parsed[4],
+		}
+	}
+	return candles, nil
+}
+
+// PercentReturns calculates close-to-close percentage returns.
+// The result has len(klines)-1 values; result[i] is the return of klines[i+1].
+func PercentReturns(klines []KlineData) ([]float64, error) {
+	candles, err := parseKlines(klines)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) < 2 {
+		return nil, fmt.Errorf("at least 2 klines are required, got %d", len(candles))
+	}
+
+	returns := make([]float64, len(candles)-1)
+	for i := 1; i < len(candles); i++ {
+		prev := candles[i-1].Close
+		if prev == 0 {
+			return nil, fmt.Errorf("kline %d has a zero close price", i-1)
+		}
+		returns[i-1] = (candles[i].Close - prev) / prev * 100
+	}
+	return returns, nil
+}
+
+// RollingVolatility calculates the standard deviation of percentage returns
+// over a rolling window. result[i] covers the window of returns ending at
+// klines[i+window], so the result has len(klines)-window values.
+func RollingVolatility(klines []KlineData, window int) ([]float64, error) {
+	if window < 2 {
+		return nil, fmt.Errorf("window must be at least 2, got %d", window)
+	}
+
+	returns, err := PercentReturns(klines)
+	if err != nil {
+		return nil, err
+	}
+	if len(returns) < window {
+		return nil, fmt.Errorf("at least %d klines are required, got %d", window+1, len(klines))
+	}
+
+	result := make([]float64, len(returns)-window+1)
+	for i := range result {
+		result[i] = stdDev(returns[i : i+window])
+	}
+	return result, nil
+}
+
+// AverageTrueRange calculates the ATR using Wilder's smoothing.
+// result[i] is the ATR at klines[i+period], so the result has
+// len(klines)-period values.
+func AverageTrueRange(klines []KlineData, period int) ([]float64, error) {
+	if period < 1 {
+		return nil, fmt.Errorf("period must be at least 1, got %d", period)
+	}
+
+	candles, err := parseKlines(klines)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) <= period {
+		return nil, fmt.Errorf("at least %d klines are required, got %d", period+1, len(candles))
+	}
+
+	trueRanges := make([]float64, len(candles)-1)
+	for i := 1; i < len(candles); i++ {
+		trueRanges[i-1] = trueRange(candles[i], candles[i-1].Close)
+	}
+
+	result := make([]float64, len(trueRanges)-period+1)
+	result[0] = mean(trueRanges[:period])
+	for i := 1; i < len(result); i++ {
+		result[i] = (result[i-1]*float64(period-1) + trueRanges[i+period-1]) / float64(period)
+	}
+	return result, nil
+}
+
+// trueRange returns the true range of a candle given the previous close
+func trueRange(c candle, prevClose float64) float64 {
+	return math.Max(c.High-c.Low, math.Max(math.Abs(c.High-prevClose), math.Abs(c.Low-prevClose)))
+}
+
+// mean returns the arithmetic mean of values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev returns the sample standard deviation of values
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,68 @@
+package pi42
+
+import (
+	"strings"
+	"sync"
+)
+
+// symbolSeparators are removed from symbols by NormalizeSymbol
+var symbolSeparators = strings.NewReplacer("/", "", "-", "", "_", "", ":", "", " ", "")
+
+// NormalizeSymbol converts a symbol to the exchange's form by upper-casing
+// it and removing separators, e.g. "BTC/INR", "btc-inr" and "btcinr" all
+// become "BTCINR"
+func NormalizeSymbol(symbol string) string {
+	return symbolSeparators.Replace(strings.ToUpper(strings.TrimSpace(symbol)))
+}
+
+// SymbolResolver normalizes symbols, resolves registered aliases and
+// validates them against the client's exchange info
+type SymbolResolver struct {
+	client  *Client
+	aliases map[string]string
+	mu      sync.RWMutex
+}
+
+// NewSymbolResolver creates a resolver validating against client's
+// exchange info
+func NewSymbolResolver(client *Client) *SymbolResolver {
+	return &SymbolResolver{
+		client:  client,
+		aliases: make(map[string]string),
+	}
+}
+
+// AddAlias registers an alternative name for a symbol, e.g. "XBTINR" for
+// "BTCINR". Both are normalized.
+func (r *SymbolResolver) AddAlias(alias, symbol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.aliases[NormalizeSymbol(alias)] = NormalizeSymbol(symbol)
+}
+
+// Resolve returns the exchange symbol for symbol or one of its aliases. If
+// exchange info is loaded, unknown symbols return an error listing close
+// matches; otherwise the normalized symbol is returned unvalidated.
+func (r *SymbolResolver) Resolve(symbol string) (string, error) {
+	normalized := NormalizeSymbol(symbol)
+
+	r.mu.RLock()
+	if target, ok := r.aliases[normalized]; ok {
+		normalized = target
+	}
+	r.mu.RUnlock()
+
+	r.client.infoMu.RLock()
+	loaded := len(r.client.ExchangeInfo) > 0
+	r.client.infoMu.RUnlock()
+	if !loaded {
+		return normalized, nil
+	}
+
+	contract, err := r.client.GetContractInfo(normalized)
+	if err != nil {
+		return "", err
+	}
+	return contract.Symbol, nil
+}
@@ -0,0 +1,35 @@
+package pi42
+
+import "math"
+
+// PriceStep returns the contract's price increment: the exchange tick size
+// when provided, otherwise one unit of the price precision
+func (ci ContractInfo) PriceStep() float64 {
+	if ci.TickSize > 0 {
+		return ci.TickSize
+	}
+	return 1 / math.Pow10(ci.PricePrecision)
+}
+
+// RoundToTick rounds price to the nearest multiple of the price step
+func (ci ContractInfo) RoundToTick(price float64) float64 {
+	step := ci.PriceStep()
+	return roundToDecimal(math.Round(price/step)*step, stepDecimals(step, ci.PricePrecision))
+}
+
+// onTick reports whether price is a multiple of the price step
+func (ci ContractInfo) onTick(price float64) bool {
+	step := ci.PriceStep()
+	steps := price / step
+	return math.Abs(steps-math.Round(steps)) <= 1e-6
+}
+
+// stepDecimals returns the number of decimals needed to represent
+// multiples of step, and at least precision
+func stepDecimals(step float64, precision int) int {
+	decimals := precision
+	for decimals < 12 && !hasPrecision(step, decimals) {
+		decimals++
+	}
+	return decimals
+}
@@ -0,0 +1,152 @@
+package pi42
+
+import (
+	"strings"
+	"sync"
+)
+
+// TickerCache keeps the latest 24hr ticker for every symbol in memory.
+// It is fed by the tickerArr event which the server broadcasts for all
+// contracts, so a single subscription replaces polling GetTicker24hr per symbol.
+type TickerCache struct {
+	socket *SocketClient
+
+	// Latest ticker per symbol, keyed by upper-case symbol
+	tickers map[string]Ticker
+	// Callbacks invoked whenever a symbol's ticker changes
+	callbacks []func(Ticker)
+	// Mutex for thread-safe access to tickers and callbacks
+	mu sync.RWMutex
+
+	done chan struct{}
+}
+
+// NewTickerCache creates a ticker cache that reads from the given socket client
+func NewTickerCache(socket *SocketClient) *TickerCache {
+	return &TickerCache{
+		socket:  socket,
+		tickers: make(map[string]Ticker),
+	}
+}
+
+// Start begins consuming tickerArr events in a background goroutine.
+// The socket client must be connected separately with Connect.
+func (tc *TickerCache) Start() {
+	ch, exists := tc.socket.GetEventChannel("tickerArr")
+	if !exists {
+		logger().Warnf("tickerArr channel not available; ticker cache not started")
+		return
+	}
+
+	tc.mu.Lock()
+	if tc.done != nil {
+		tc.mu.Unlock()
+		return // Already running
+	}
+	tc.done = make(chan struct{})
+	done := tc.done
+	tc.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case event, open := <-ch:
+				if !open {
+					return // Socket client was closed
+				}
+				tickers, ok := event.Payload.([]Ticker)
+				if !ok {
+					logger().Warnf("Could not decode tickerArr event: %v", event.Data)
+					continue
+				}
+				tc.update(tickers)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops consuming tickerArr events. Cached tickers remain available.
+func (tc *TickerCache) Stop() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.done != nil {
+		close(tc.done)
+		tc.done = nil
+	}
+}
+
+// OnChange registers a callback invoked with every ticker whose values changed
+func (tc *TickerCache) OnChange(callback func(Ticker)) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.callbacks = append(tc.callbacks, callback)
+}
+
+// Get returns the latest ticker for a symbol
+func (tc *TickerCache) Get(symbol string) (Ticker, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	ticker, ok := tc.tickers[strings.ToUpper(symbol)]
+	return ticker, ok
+}
+
+// All returns a snapshot of all cached tickers keyed by symbol
+func (tc *TickerCache) All() map[string]Ticker {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	snapshot := make(map[string]Ticker, len(tc.tickers))
+	for symbol, ticker := range tc.tickers {
+		snapshot[symbol] = ticker
+	}
+	return snapshot
+}
+
+// update stores new tickers and notifies callbacks for the ones that changed
+func (tc *TickerCache) update(tickers []Ticker) {
+	var changed []Ticker
+
+	tc.mu.Lock()
+	for _, ticker := range tickers {
+		if ticker.Symbol == "" {
+			continue
+		}
+		symbol := strings.ToUpper(ticker.Symbol)
+		if previous, ok := tc.tickers[symbol]; ok && previous == ticker {
+			continue
+		}
+		tc.tickers[symbol] = ticker
+		changed = append(changed, ticker)
+	}
+	callbacks := append([]func(Ticker){}, tc.callbacks...)
+	tc.mu.Unlock()
+
+	// Invoke callbacks outside the lock so they may query the cache
+	for _, ticker := range changed {
+		for _, callback := range callbacks {
+			callback(ticker)
+		}
+	}
+}
+
+// decodeTickerArr parses a tickerArr payload, which is either a bare array of
+// tickers or an object wrapping the array in a data field
+func decodeTickerArr(data []any) ([]Ticker, error) {
+	var tickers []Ticker
+	if err := decodeEventPayload(data, &tickers); err == nil {
+		return tickers, nil
+	}
+
+	var wrapped struct {
+		Data []Ticker `json:"data"`
+	}
+	if err := decodeEventPayload(data, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.Data, nil
+}
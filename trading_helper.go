@@ -3,11 +3,18 @@ package pi42
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
+	"sync"
+	"time"
 )
 
 // TradingHelper provides convenient access to symbol-specific trading parameters
-// like minimum quantities, price precision, and price step sizes
+// like minimum quantities, price precision, and price step sizes.
+//
+// A TradingHelper is safe for concurrent use through its methods. The
+// exported fields are updated by Refresh, so read them through the getters
+// and MarketState while another goroutine may be refreshing the helper.
 type TradingHelper struct {
 	// Symbol information
 	Symbol       string
@@ -27,11 +34,36 @@ type TradingHelper struct {
 	PricePrecision int     // Number of decimal places for price
 	MinPriceStep   float64 // Minimum price increment
 
+	// Minimum order value in the quote asset; zero if not enforced
+	MinNotional float64
+	// Raise quantities below the minimum notional to the minimum instead
+	// of rejecting them
+	BumpToMinNotional bool
+
+	// Fee rates as percentages of the notional (e.g. 0.045 for 0.045%)
+	MakerFee float64
+	TakerFee float64
+
 	// Derived values
 	PercentIncrement float64 // Percentage of price difference between steps
 
+	// Market state from the last refresh
+	LastPrice float64
+	BestBid   float64
+	BestAsk   float64
+	UpdatedAt time.Time
+
 	// Reference to client for market data access
 	client *Client
+	// Market API used for ticker and depth requests; client.Market unless
+	// the helper was built with a cache of its own
+	market *MarketAPI
+	// Optional live price sources preferred over REST requests
+	orderBook *LiveOrderBook
+	tickers   *TickerCache
+
+	// Mutex for thread-safe access to the fields above
+	mu sync.RWMutex
 }
 
 // NewTradingHelper creates a new TradingHelper for a specific symbol
@@ -42,6 +74,7 @@ func NewTradingHelper(client *Client, symbol string, percentIncrement float64) (
 		Symbol:           symbol,
 		PercentIncrement: percentIncrement,
 		client:           client,
+		market:           client.Market,
 	}
 
 	// Initialize the helper with contract specifications
@@ -55,19 +88,35 @@ func NewTradingHelper(client *Client, symbol string, percentIncrement float64) (
 // init loads all necessary trading parameters from the exchange
 func (th *TradingHelper) init() error {
 	// First check if we already have the contract info cached in the client
-	contractInfo, exists := th.client.ExchangeInfo[th.Symbol]
-	if !exists {
+	contractInfo, err := th.client.GetContractInfo(th.Symbol)
+	if err != nil {
 		// If not cached, try to fetch exchange info
-		if err := th.client.fetchExchangeInfo(); err != nil {
+		if _, err := th.client.fetchExchangeInfo(); err != nil {
 			return fmt.Errorf("failed to fetch exchange info: %v", err)
 		}
 
 		// Check again after fetching
-		contractInfo, exists = th.client.ExchangeInfo[th.Symbol]
-		if !exists {
-			return fmt.Errorf("symbol %s not found in exchange info", th.Symbol)
+		contractInfo, err = th.client.GetContractInfo(th.Symbol)
+		if err != nil {
+			return err
 		}
 	}
+	th.applyContract(contractInfo)
+
+	// Get current market price to calculate percentage-based increments
+	if err := th.updateCurrentPrice(); err != nil {
+		return fmt.Errorf("failed to get current price: %v", err)
+	}
+
+	return nil
+}
+
+// applyContract populates the contract-derived fields
+func (th *TradingHelper) applyContract(contractInfo ContractInfo) {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+
+	th.Symbol = contractInfo.Symbol
 
 	// Populate fields from contract info
 	th.BaseAsset = contractInfo.BaseAsset
@@ -77,28 +126,33 @@ func (th *TradingHelper) init() error {
 	th.PricePrecision = contractInfo.PricePrecision
 	th.MinQuantity = contractInfo.MinQuantity
 	th.MaxQuantity = contractInfo.MaxQuantity
+	th.MinNotional = contractInfo.MinNotional
+	th.MakerFee = contractInfo.MakerFee
+	th.TakerFee = contractInfo.TakerFee
 
-	// Set default margin asset if available
+	// Set default margin asset if available, keeping the current one if
+	// it is still supported
 	if len(contractInfo.MarginAssets) > 0 {
-		th.MarginAsset = contractInfo.MarginAssets[0]
+		if !slices.Contains(contractInfo.MarginAssets, th.MarginAsset) {
+			th.MarginAsset = contractInfo.MarginAssets[0]
+		}
 	} else {
 		th.MarginAsset = contractInfo.QuoteAsset
 	}
 
-	// Calculate minimum price step based on precision
-	th.MinPriceStep = 1.0 / math.Pow10(th.PricePrecision)
-
-	// Get current market price to calculate percentage-based increments
-	if err := th.updateCurrentPrice(); err != nil {
-		return fmt.Errorf("failed to get current price: %v", err)
-	}
-
-	return nil
+	// Use the exchange tick size, falling back to the price precision
+	th.MinPriceStep = contractInfo.PriceStep()
 }
 
 // updateCurrentPrice gets the latest market price for the symbol
 func (th *TradingHelper) updateCurrentPrice() error {
-	ticker, err := th.client.Market.GetTicker24hr(th.Symbol)
+	// Prefer the live ticker cache when one is attached
+	if lastPrice, ok := th.liveLastPrice(); ok {
+		th.setLastPrice(lastPrice)
+		return nil
+	}
+
+	ticker, err := th.market.GetTicker24hr(th.symbol())
 	if err != nil {
 		return err
 	}
@@ -118,38 +172,130 @@ func (th *TradingHelper) updateCurrentPrice() error {
 		return fmt.Errorf("could not convert price to float: %v", err)
 	}
 
+	th.setLastPrice(currentPrice)
+
+	return nil
+}
+
+// setLastPrice records the latest market price for the symbol
+func (th *TradingHelper) setLastPrice(currentPrice float64) {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+
 	// Set MaxPrice to a high multiple of current price
+	th.LastPrice = currentPrice
 	th.MaxPrice = currentPrice * 10
+	th.UpdatedAt = time.Now()
+}
 
-	return nil
+// RoundPrice rounds price to the contract's price step without making it
+// more aggressive: buy prices are rounded down and sell prices up.
+func (th *TradingHelper) RoundPrice(price float64, side OrderSide) float64 {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	if th.MinPriceStep <= 0 {
+		return price
+	}
+
+	steps := price / th.MinPriceStep
+	if side == OrderSideSell {
+		steps = math.Ceil(steps - 1e-9)
+	} else {
+		steps = math.Floor(steps + 1e-9)
+	}
+
+	return roundToDecimal(steps*th.MinPriceStep, stepDecimals(th.MinPriceStep, th.PricePrecision))
+}
+
+// RoundQuantity rounds qty down to the contract's quantity precision so an
+// order never exceeds the requested size
+func (th *TradingHelper) RoundQuantity(qty float64) float64 {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	return th.roundQuantity(qty)
+}
+
+// roundQuantity rounds qty down to the quantity precision; the caller must
+// hold the lock
+func (th *TradingHelper) roundQuantity(qty float64) float64 {
+	multiplier := math.Pow10(th.QuantityPrecision)
+	return math.Floor(qty*multiplier+1e-9) / multiplier
+}
+
+// symbol returns the helper's contract symbol
+func (th *TradingHelper) symbol() string {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	return th.Symbol
 }
 
 // GetMinimumOrderQuantity returns the minimum quantity allowed for orders
 func (th *TradingHelper) GetMinimumOrderQuantity() float64 {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
 	return th.MinQuantity
 }
 
 // GetMinimumPriceIncrement returns the smallest price step allowed
 func (th *TradingHelper) GetMinimumPriceIncrement() float64 {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
 	return th.MinPriceStep
 }
 
 // GetPricePrecision returns the number of decimal places for price
 func (th *TradingHelper) GetPricePrecision() int {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
 	return th.PricePrecision
 }
 
 // GetQuantityPrecision returns the number of decimal places for quantity
 func (th *TradingHelper) GetQuantityPrecision() int {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
 	return th.QuantityPrecision
 }
 
 // GetMarginAsset returns the default margin asset for this symbol
 func (th *TradingHelper) GetMarginAsset() string {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
 	return th.MarginAsset
 
 }
 
+// MarketState holds the market prices captured by the last refresh
+type MarketState struct {
+	LastPrice float64
+	MaxPrice  float64
+	BestBid   float64
+	BestAsk   float64
+	UpdatedAt time.Time
+}
+
+// MarketState returns the market prices captured by the last refresh
+func (th *TradingHelper) MarketState() MarketState {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	return MarketState{
+		LastPrice: th.LastPrice,
+		MaxPrice:  th.MaxPrice,
+		BestBid:   th.BestBid,
+		BestAsk:   th.BestAsk,
+		UpdatedAt: th.UpdatedAt,
+	}
+}
+
 // SymbolInfo contains basic information about a trading symbol
 type SymbolInfo struct {
 	Symbol            string  `json:"symbol"`
@@ -167,6 +313,9 @@ type SymbolInfo struct {
 
 // GetSymbolInfo returns basic information about the symbol
 func (th *TradingHelper) GetSymbolInfo() SymbolInfo {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
 	return SymbolInfo{
 		Symbol:            th.Symbol,
 		BaseAsset:         th.BaseAsset,
@@ -185,51 +334,74 @@ func (th *TradingHelper) GetSymbolInfo() SymbolInfo {
 // CalculatePriceFromBestPrice calculates a price at a specified percentage difference
 // from the best bid/ask price. Positive percentDiff for above, negative for below.
 func (th *TradingHelper) CalculatePriceFromBestPrice(percentDiff float64) (float64, error) {
-	// Get depth data to find best bid/ask
-	depth, err := th.client.Market.GetDepth(th.Symbol)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get order book depth: %v", err)
-	}
-
-	var bestPrice float64
-
 	// For positive percentDiff, we start from the best ask (for buy orders)
 	// For negative percentDiff, we start from the best bid (for sell orders)
-	if percentDiff > 0 {
-		// Use best ask (lowest sell price) as reference
-		if len(depth.Data.Asks) > 0 {
-			bestPrice, err = strconv.ParseFloat(depth.Data.Asks[0][0], 64)
-			if err != nil {
-				return 0, fmt.Errorf("could not parse ask price: %v", err)
-			}
-		} else {
-			return 0, fmt.Errorf("no ask prices available in order book")
+	var bestPrice float64
+	if bestBid, bestAsk, ok := th.liveBestPrices(); ok {
+		bestPrice = bestBid
+		if percentDiff > 0 {
+			bestPrice = bestAsk
 		}
 	} else {
-		// Use best bid (highest buy price) as reference
-		if len(depth.Data.Bids) > 0 {
-			bestPrice, err = strconv.ParseFloat(depth.Data.Bids[0][0], 64)
-			if err != nil {
-				return 0, fmt.Errorf("could not parse bid price: %v", err)
-			}
-		} else {
-			return 0, fmt.Errorf("no bid prices available in order book")
+		var err error
+		if bestPrice, err = th.fetchBestPrice(percentDiff > 0); err != nil {
+			return 0, err
 		}
 	}
 
 	// Calculate target price with percentage difference
 	targetPrice := bestPrice * (1 + percentDiff/100)
 
-	// Round to the correct precision
-	targetPrice = math.Round(targetPrice/th.MinPriceStep) * th.MinPriceStep
+	// Round to the price step away from the opposite side of the book
+	side := OrderSideSell
+	if percentDiff > 0 {
+		side = OrderSideBuy
+	}
 
-	return targetPrice, nil
+	return th.RoundPrice(targetPrice, side), nil
+}
+
+// fetchBestPrice gets the best ask, or the best bid if ask is false,
+// from the REST order book
+func (th *TradingHelper) fetchBestPrice(ask bool) (float64, error) {
+	// Get depth data to find best bid/ask
+	depth, err := th.market.GetDepthLimit(th.symbol(), 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get order book depth: %v", err)
+	}
+
+	if ask {
+		// Use best ask (lowest sell price) as reference
+		if len(depth.Data.Asks) == 0 {
+			return 0, fmt.Errorf("no ask prices available in order book")
+		}
+		bestPrice, err := strconv.ParseFloat(depth.Data.Asks[0][0], 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse ask price: %v", err)
+		}
+		return bestPrice, nil
+	}
+
+	// Use best bid (highest buy price) as reference
+	if len(depth.Data.Bids) == 0 {
+		return 0, fmt.Errorf("no bid prices available in order book")
+	}
+	bestPrice, err := strconv.ParseFloat(depth.Data.Bids[0][0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse bid price: %v", err)
+	}
+	return bestPrice, nil
 }
 
 // GetCurrentBestPrices returns the current best bid and ask prices
 func (th *TradingHelper) GetCurrentBestPrices() (float64, float64, error) {
+	// Prefer the live order book when one is attached
+	if bestBid, bestAsk, ok := th.liveBestPrices(); ok {
+		return bestBid, bestAsk, nil
+	}
+
 	// Get depth data to find best bid/ask
-	depth, err := th.client.Market.GetDepth(th.Symbol)
+	depth, err := th.market.GetDepthLimit(th.symbol(), 1)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get order book depth: %v", err)
 	}
@@ -259,36 +431,124 @@ func (th *TradingHelper) GetCurrentBestPrices() (float64, float64, error) {
 	return bestBid, bestAsk, nil
 }
 
+// PriceReference selects which side of the book prices conversions
+type PriceReference string
+
+const (
+	PriceReferenceBid PriceReference = "BID"
+	PriceReferenceAsk PriceReference = "ASK"
+	PriceReferenceMid PriceReference = "MID"
+)
+
+// PriceReferenceFor returns the price an order on side would trade at:
+// the ask for buys and the bid for sells
+func PriceReferenceFor(side OrderSide) PriceReference {
+	if side == OrderSideSell {
+		return PriceReferenceBid
+	}
+	return PriceReferenceAsk
+}
+
+// ReferencePrice returns the current best bid, best ask or their average
+func (th *TradingHelper) ReferencePrice(reference PriceReference) (float64, error) {
+	bestBid, bestAsk, err := th.GetCurrentBestPrices()
+	if err != nil {
+		return 0, err
+	}
+
+	switch reference {
+	case PriceReferenceBid:
+		return bestBid, nil
+	case PriceReferenceAsk:
+		return bestAsk, nil
+	case PriceReferenceMid:
+		return (bestBid + bestAsk) / 2, nil
+	default:
+		return 0, fmt.Errorf("unknown price reference %q", reference)
+	}
+}
+
 // CalculateOrderQuantity calculates order quantity in base asset units
 // from an amount in quote asset (e.g., INR amount to BTC quantity)
+// using the average of the best bid and ask
 func (th *TradingHelper) CalculateOrderQuantity(quoteAmount float64) (float64, error) {
+	return th.CalculateOrderQuantityAt(quoteAmount, PriceReferenceMid)
+}
+
+// CalculateOrderQuantityAt calculates order quantity in base asset units
+// from an amount in quote asset, priced at the given reference
+func (th *TradingHelper) CalculateOrderQuantityAt(quoteAmount float64, reference PriceReference) (float64, error) {
 	// Get current price to calculate conversion
-	bestBid, bestAsk, err := th.GetCurrentBestPrices()
+	price, err := th.ReferencePrice(reference)
 	if err != nil {
 		return 0, err
 	}
 
-	// Use average of bid/ask for calculation
-	averagePrice := (bestBid + bestAsk) / 2
-
 	// Calculate quantity
-	quantity := quoteAmount / averagePrice
+	quantity, err := th.applyMinNotional(quoteAmount/price, price)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := th.checkQuantity(quantity); err != nil {
+		return 0, err
+	}
+
+	return th.RoundQuantity(quantity), nil
+}
+
+// applyMinNotional checks that quantity at price meets the minimum notional,
+// raising it to the minimum if BumpToMinNotional is set
+func (th *TradingHelper) applyMinNotional(quantity, price float64) (float64, error) {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	notional := th.roundQuantity(quantity) * price
+	if th.MinNotional <= 0 || notional >= th.MinNotional {
+		return quantity, nil
+	}
+
+	if !th.BumpToMinNotional {
+		return 0, fmt.Errorf("order value %.8f is below minimum notional %.8f",
+			notional, th.MinNotional)
+	}
+
+	// Round up so the rounded quantity still meets the minimum
+	multiplier := math.Pow10(th.QuantityPrecision)
+	bumped := math.Ceil(th.MinNotional/price*multiplier-1e-9) / multiplier
+	logger().Warnf("Raising %s quantity from %v to %v to meet minimum notional %v",
+		th.Symbol, th.roundQuantity(quantity), bumped, th.MinNotional)
+
+	return bumped, nil
+}
+
+// CalculateQuoteAmount calculates the value in quote asset of a quantity
+// in base asset units, priced at the given reference
+func (th *TradingHelper) CalculateQuoteAmount(quantity float64, reference PriceReference) (float64, error) {
+	price, err := th.ReferencePrice(reference)
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity * price, nil
+}
+
+// checkQuantity checks a calculated quantity against the contract limits
+func (th *TradingHelper) checkQuantity(quantity float64) error {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
 
 	// Check against minimum
 	if quantity < th.MinQuantity {
-		return 0, fmt.Errorf("calculated quantity %.8f is below minimum allowed %.8f",
+		return fmt.Errorf("calculated quantity %.8f is below minimum allowed %.8f",
 			quantity, th.MinQuantity)
 	}
 
 	// Check against maximum
 	if th.MaxQuantity > 0 && quantity > th.MaxQuantity {
-		return 0, fmt.Errorf("calculated quantity %.8f is above maximum allowed %.8f",
+		return fmt.Errorf("calculated quantity %.8f is above maximum allowed %.8f",
 			quantity, th.MaxQuantity)
 	}
 
-	// Round to the correct precision
-	precisionMultiplier := math.Pow10(th.QuantityPrecision)
-	quantity = math.Floor(quantity*precisionMultiplier) / precisionMultiplier
-
-	return quantity, nil
+	return nil
 }
@@ -0,0 +1,122 @@
+package pi42
+
+import "fmt"
+
+// ExecutionEstimate describes how a market order would fill against the
+// current order book
+type ExecutionEstimate struct {
+	Side           OrderSide
+	Quantity       float64 // Requested quantity
+	FilledQuantity float64 // Quantity the visible book can fill
+	Residual       float64 // Quantity left unfilled for lack of liquidity
+	AveragePrice   float64 // Volume-weighted price of the filled quantity
+	WorstPrice     float64 // Price of the last level touched
+	Cost           float64 // Total quote value of the filled quantity
+	Levels         int     // Number of levels consumed
+}
+
+// ExecutablePrice walks the order book levels an order on side would take
+// (asks for buys, bids for sells) and returns the average price and cost of
+// filling qty. If the book is too thin the estimate covers what can be
+// filled and reports the remainder as Residual.
+func (th *TradingHelper) ExecutablePrice(side OrderSide, qty float64) (ExecutionEstimate, error) {
+	if qty <= 0 {
+		return ExecutionEstimate{}, fmt.Errorf("quantity must be positive, got %v", qty)
+	}
+
+	bids, asks, err := th.bookLevels()
+	if err != nil {
+		return ExecutionEstimate{}, err
+	}
+
+	levels := asks
+	if side == OrderSideSell {
+		levels = bids
+	}
+	if len(levels) == 0 {
+		return ExecutionEstimate{}, fmt.Errorf("no liquidity on the %s side of the %s order book", side, th.symbol())
+	}
+
+	return walkLevels(side, levels, qty), nil
+}
+
+// EstimateSlippage returns the expected slippage of a market order for qty
+// on side, in basis points of the mid price. Positive values are a cost.
+// It returns an error if the visible book cannot fill the whole quantity.
+func (th *TradingHelper) EstimateSlippage(side OrderSide, qty float64) (float64, error) {
+	if qty <= 0 {
+		return 0, fmt.Errorf("quantity must be positive, got %v", qty)
+	}
+
+	bids, asks, err := th.bookLevels()
+	if err != nil {
+		return 0, err
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return 0, fmt.Errorf("order book for %s is empty", th.symbol())
+	}
+	mid := (bids[0].Price + asks[0].Price) / 2
+
+	levels := asks
+	if side == OrderSideSell {
+		levels = bids
+	}
+	estimate := walkLevels(side, levels, qty)
+	if estimate.Residual > 0 {
+		return 0, fmt.Errorf("order book for %s is too thin to fill %v, %v unfilled",
+			th.symbol(), qty, estimate.Residual)
+	}
+
+	slippage := (estimate.AveragePrice - mid) / mid * 10000
+	if side == OrderSideSell {
+		slippage = -slippage
+	}
+	return slippage, nil
+}
+
+// bookLevels returns both sides of the order book, best price first
+func (th *TradingHelper) bookLevels() ([]PriceLevel, []PriceLevel, error) {
+	if orderBook, _ := th.priceSources(); orderBook != nil && orderBook.Synced() {
+		book := orderBook.Book()
+		return book.Bids(0), book.Asks(0), nil
+	}
+
+	depth, err := th.market.GetDepth(th.symbol())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get order book depth: %v", err)
+	}
+
+	bids, err := parseLevels(depth.Data.Bids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse bid levels: %v", err)
+	}
+	asks, err := parseLevels(depth.Data.Asks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse ask levels: %v", err)
+	}
+	return bids, asks, nil
+}
+
+// walkLevels fills qty against levels ordered best price first
+func walkLevels(side OrderSide, levels []PriceLevel, qty float64) ExecutionEstimate {
+	estimate := ExecutionEstimate{Side: side, Quantity: qty}
+
+	remaining := qty
+	for _, level := range levels {
+		if remaining <= 0 {
+			break
+		}
+		fill := min(remaining, level.Quantity)
+		estimate.FilledQuantity += fill
+		estimate.Cost += fill * level.Price
+		estimate.WorstPrice = level.Price
+		estimate.Levels++
+		remaining -= fill
+	}
+
+	estimate.Residual = max(remaining, 0)
+	if estimate.FilledQuantity > 0 {
+		estimate.AveragePrice = estimate.Cost / estimate.FilledQuantity
+	}
+	return estimate
+}
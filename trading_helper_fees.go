@@ -0,0 +1,52 @@
+package pi42
+
+// FeeRole identifies whether a fill adds liquidity (maker) or removes it
+// (taker), which determines the fee rate charged
+type FeeRole string
+
+const (
+	FeeRoleMaker FeeRole = "MAKER"
+	FeeRoleTaker FeeRole = "TAKER"
+)
+
+// FeeRate returns the contract's fee rate for a role as a fraction of the
+// notional (e.g. 0.00045 for 0.045%)
+func (th *TradingHelper) FeeRate(role FeeRole) float64 {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	if role == FeeRoleMaker {
+		return th.MakerFee / 100
+	}
+	return th.TakerFee / 100
+}
+
+// Fee returns the fee for filling qty at price in the quote asset
+func (th *TradingHelper) Fee(qty, price float64, role FeeRole) float64 {
+	return qty * price * th.FeeRate(role)
+}
+
+// NetPnL returns the profit or loss in the quote asset of entering a
+// position with an order on side at entryPrice and closing it at
+// exitPrice, after the entry and exit fees
+func (th *TradingHelper) NetPnL(side OrderSide, qty, entryPrice, exitPrice float64, entryRole, exitRole FeeRole) float64 {
+	gross := (exitPrice - entryPrice) * qty
+	if side == OrderSideSell {
+		gross = -gross
+	}
+	return gross - th.Fee(qty, entryPrice, entryRole) - th.Fee(qty, exitPrice, exitRole)
+}
+
+// BreakEvenPrice returns the exit price at which a position entered with an
+// order on side at entryPrice nets zero after fees. The price is rounded to
+// the price step on the side that keeps the exit at or above break-even.
+func (th *TradingHelper) BreakEvenPrice(side OrderSide, entryPrice float64, entryRole, exitRole FeeRole) float64 {
+	entryRate := th.FeeRate(entryRole)
+	exitRate := th.FeeRate(exitRole)
+
+	if side == OrderSideSell {
+		// A short is closed by buying, so round down
+		return th.RoundPrice(entryPrice*(1-entryRate)/(1+exitRate), OrderSideBuy)
+	}
+	return th.RoundPrice(entryPrice*(1+entryRate)/(1-exitRate), OrderSideSell)
+}
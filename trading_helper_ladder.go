@@ -0,0 +1,95 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+)
+
+// LadderSpacing selects how PriceLadder spaces its levels
+type LadderSpacing string
+
+const (
+	// LadderArithmetic spaces levels by a constant price difference
+	LadderArithmetic LadderSpacing = "ARITHMETIC"
+	// LadderGeometric spaces levels by a constant percentage
+	LadderGeometric LadderSpacing = "GEOMETRIC"
+)
+
+// PriceLadder returns levels prices from from to to inclusive, for placing
+// grid orders. Prices are rounded to the price step; levels that round to
+// the same price are returned once.
+func (th *TradingHelper) PriceLadder(from, to float64, levels int, spacing LadderSpacing) ([]float64, error) {
+	if from <= 0 || to <= 0 {
+		return nil, fmt.Errorf("ladder bounds must be positive")
+	}
+	if levels < 2 {
+		return nil, fmt.Errorf("ladder needs at least 2 levels, got %d", levels)
+	}
+
+	step, decimals := th.ladderStep()
+	prices := make([]float64, 0, levels)
+	for i := 0; i < levels; i++ {
+		fraction := float64(i) / float64(levels-1)
+
+		var price float64
+		switch spacing {
+		case LadderArithmetic:
+			price = from + (to-from)*fraction
+		case LadderGeometric:
+			price = from * math.Pow(to/from, fraction)
+		default:
+			return nil, fmt.Errorf("unknown ladder spacing %q", spacing)
+		}
+		prices = appendLadderPrice(prices, price, step, decimals)
+	}
+
+	return prices, nil
+}
+
+// PriceLadderPercent returns prices from from towards to, each pct percent
+// away from the previous one, stopping at the last level that does not go
+// past to. Prices are rounded to the price step.
+func (th *TradingHelper) PriceLadderPercent(from, to, pct float64) ([]float64, error) {
+	if from <= 0 || to <= 0 {
+		return nil, fmt.Errorf("ladder bounds must be positive")
+	}
+	if pct <= 0 {
+		return nil, fmt.Errorf("ladder step must be positive, got %v%%", pct)
+	}
+
+	factor := 1 + pct/100
+	if to < from {
+		factor = 1 / factor
+	}
+
+	step, decimals := th.ladderStep()
+	var prices []float64
+	for price := from; ; price *= factor {
+		if (to >= from && price > to*(1+1e-9)) || (to < from && price < to*(1-1e-9)) {
+			break
+		}
+		prices = appendLadderPrice(prices, price, step, decimals)
+	}
+
+	return prices, nil
+}
+
+// ladderStep returns the price step and the decimals its multiples need
+func (th *TradingHelper) ladderStep() (float64, int) {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	return th.MinPriceStep, stepDecimals(th.MinPriceStep, th.PricePrecision)
+}
+
+// appendLadderPrice rounds price to the nearest step and appends it unless
+// it equals the previous level
+func appendLadderPrice(prices []float64, price, step float64, decimals int) []float64 {
+	if step > 0 {
+		price = roundToDecimal(math.Round(price/step)*step, decimals)
+	}
+	if len(prices) > 0 && prices[len(prices)-1] == price {
+		return prices
+	}
+	return append(prices, price)
+}
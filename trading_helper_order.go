@@ -0,0 +1,96 @@
+package pi42
+
+import "fmt"
+
+// OrderRequest describes an order for TradingHelper.BuildOrder. Set
+// exactly one of Quantity and Notional.
+type OrderRequest struct {
+	Side OrderSide
+	Type OrderType
+	// Limit price for LIMIT and STOP_LIMIT orders
+	Price float64
+	// Trigger price for STOP_MARKET and STOP_LIMIT orders
+	StopPrice float64
+	// Order size in base asset units
+	Quantity float64
+	// Order size in quote asset, converted at the order price or, for
+	// market orders, the price the order would trade at
+	Notional        float64
+	TakeProfitPrice float64
+	StopLossPrice   float64
+	ReduceOnly      bool
+	Leverage        int
+}
+
+// BuildOrder turns a request into PlaceOrderParams ready for
+// OrderAPI.PlaceOrder. Prices are rounded to the price step (limit prices
+// away from the book), the quantity is rounded down to the quantity
+// precision and the result is checked against the contract filters.
+// Filter violations are returned as an OrderValidationError.
+func (th *TradingHelper) BuildOrder(req OrderRequest) (PlaceOrderParams, error) {
+	if req.Side != OrderSideBuy && req.Side != OrderSideSell {
+		return PlaceOrderParams{}, fmt.Errorf("invalid order side %q", req.Side)
+	}
+	if (req.Quantity > 0) == (req.Notional > 0) {
+		return PlaceOrderParams{}, fmt.Errorf("exactly one of quantity and notional must be set")
+	}
+
+	th.mu.RLock()
+	symbol, marginAsset := th.Symbol, th.MarginAsset
+	th.mu.RUnlock()
+
+	contract, err := th.client.GetContractInfo(symbol)
+	if err != nil {
+		return PlaceOrderParams{}, err
+	}
+
+	params := PlaceOrderParams{
+		Symbol:          symbol,
+		Side:            req.Side,
+		Type:            req.Type,
+		PlaceType:       "ORDER_FORM",
+		MarginAsset:     marginAsset,
+		ReduceOnly:      req.ReduceOnly,
+		Leverage:        req.Leverage,
+		TakeProfitPrice: contract.RoundToTick(req.TakeProfitPrice),
+		StopLossPrice:   contract.RoundToTick(req.StopLossPrice),
+	}
+	if req.Price > 0 {
+		params.Price = th.RoundPrice(req.Price, req.Side)
+	}
+	if req.StopPrice > 0 {
+		params.StopPrice = contract.RoundToTick(req.StopPrice)
+	}
+
+	// Market orders are valued at the price they would trade at
+	var marketPrice float64
+	isMarket := req.Type == OrderTypeMarket || req.Type == OrderTypeStopMarket
+	if isMarket || (req.Notional > 0 && params.Price == 0) {
+		if marketPrice, err = th.ReferencePrice(PriceReferenceFor(req.Side)); err != nil {
+			return PlaceOrderParams{}, err
+		}
+	}
+
+	if req.Notional > 0 {
+		price := params.Price
+		if price == 0 {
+			price = marketPrice
+		}
+		// Reduce-only orders are exempt from the minimum notional
+		quantity := req.Notional / price
+		if !req.ReduceOnly {
+			if quantity, err = th.applyMinNotional(quantity, price); err != nil {
+				return PlaceOrderParams{}, err
+			}
+		}
+		params.Quantity = th.RoundQuantity(quantity)
+	} else {
+		params.Quantity = th.RoundQuantity(req.Quantity)
+	}
+
+	if violations := contract.ValidateOrderAt(params, marketPrice); len(violations) > 0 {
+		return PlaceOrderParams{}, OrderValidationError{Symbol: symbol, Violations: violations}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,67 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Refresh reloads the contract values from the client's exchange info and
+// the last price, MaxPrice and best bid/ask from the market. Keep the
+// exchange info itself current with Client.AutoRefreshExchangeInfo.
+func (th *TradingHelper) Refresh(ctx context.Context) error {
+	contractInfo, err := th.client.GetContractInfo(th.symbol())
+	if err != nil {
+		return err
+	}
+	th.applyContract(contractInfo)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := th.updateCurrentPrice(); err != nil {
+		return fmt.Errorf("failed to get current price: %v", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bestBid, bestAsk, err := th.GetCurrentBestPrices()
+	if err != nil {
+		return err
+	}
+	th.mu.Lock()
+	th.BestBid = bestBid
+	th.BestAsk = bestAsk
+	th.UpdatedAt = time.Now()
+	th.mu.Unlock()
+
+	return nil
+}
+
+// AutoRefresh refreshes the helper every interval until ctx is done.
+// Failed refreshes are logged and retried at the next interval. It returns
+// immediately if interval is not positive.
+//
+// Cancelling ctx stops the loop, but a refresh already in flight is not
+// interrupted: Refresh only checks ctx between its requests, so AutoRefresh
+// may return up to one request's duration after ctx is done.
+func (th *TradingHelper) AutoRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		logger().Warnf("Not refreshing trading helper for %s: invalid interval %v", th.symbol(), interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := th.Refresh(ctx); err != nil && ctx.Err() == nil {
+				logger().Warnf("Could not refresh trading helper for %s: %v", th.symbol(), err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
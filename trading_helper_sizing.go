@@ -0,0 +1,46 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+)
+
+// SizeByRisk returns the quantity that loses riskPercent of accountBalance
+// if the position is stopped out at stopPrice. The quantity is rounded down
+// to the contract precision and checked against the contract limits.
+func (th *TradingHelper) SizeByRisk(accountBalance, riskPercent, entryPrice, stopPrice float64) (float64, error) {
+	if accountBalance <= 0 {
+		return 0, fmt.Errorf("account balance must be positive, got %v", accountBalance)
+	}
+	if riskPercent <= 0 || riskPercent > 100 {
+		return 0, fmt.Errorf("risk percent must be between 0 and 100, got %v", riskPercent)
+	}
+	if entryPrice <= 0 || stopPrice <= 0 {
+		return 0, fmt.Errorf("entry and stop prices must be positive")
+	}
+
+	stopDistance := math.Abs(entryPrice - stopPrice)
+	if stopDistance == 0 {
+		return 0, fmt.Errorf("stop price must differ from entry price")
+	}
+
+	riskAmount := accountBalance * riskPercent / 100
+	quantity := th.RoundQuantity(riskAmount / stopDistance)
+
+	if err := th.checkQuantity(quantity); err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// EstimateMargin returns the initial margin, including the contract's margin
+// buffer, required to open qty at price and leverage, in the helper's
+// margin asset
+func (th *TradingHelper) EstimateMargin(qty, price float64, leverage int) (float64, error) {
+	th.mu.RLock()
+	symbol, marginAsset := th.Symbol, th.MarginAsset
+	th.mu.RUnlock()
+
+	return th.client.RequiredMargin(symbol, qty, price, leverage, marginAsset)
+}
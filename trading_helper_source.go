@@ -0,0 +1,69 @@
+package pi42
+
+import "strconv"
+
+// UseOrderBook makes the helper read best prices and depth from a live
+// order book instead of requesting the REST depth on every call. The REST
+// endpoint is still used while the book is not synced. Pass nil to detach.
+// Note that a grouped depth stream aggregates levels to the grouping step.
+func (th *TradingHelper) UseOrderBook(book *LiveOrderBook) {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+
+	th.orderBook = book
+}
+
+// UseTickerCache makes Refresh read the last price from a ticker cache
+// instead of requesting the REST ticker, falling back to REST when the
+// cache has no ticker for the symbol. Pass nil to detach.
+func (th *TradingHelper) UseTickerCache(cache *TickerCache) {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+
+	th.tickers = cache
+}
+
+// priceSources returns the attached order book and ticker cache
+func (th *TradingHelper) priceSources() (*LiveOrderBook, *TickerCache) {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	return th.orderBook, th.tickers
+}
+
+// liveBestPrices returns the best bid and ask from the attached order book
+// if it is synced and has both sides
+func (th *TradingHelper) liveBestPrices() (float64, float64, bool) {
+	orderBook, _ := th.priceSources()
+	if orderBook == nil || !orderBook.Synced() {
+		return 0, 0, false
+	}
+
+	bid, ok := orderBook.BestBid()
+	if !ok {
+		return 0, 0, false
+	}
+	ask, ok := orderBook.BestAsk()
+	if !ok {
+		return 0, 0, false
+	}
+	return bid.Price, ask.Price, true
+}
+
+// liveLastPrice returns the last price from the attached ticker cache
+func (th *TradingHelper) liveLastPrice() (float64, bool) {
+	_, tickers := th.priceSources()
+	if tickers == nil {
+		return 0, false
+	}
+
+	ticker, ok := tickers.Get(th.symbol())
+	if !ok {
+		return 0, false
+	}
+	lastPrice, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil || lastPrice <= 0 {
+		return 0, false
+	}
+	return lastPrice, true
+}
@@ -0,0 +1,82 @@
+package pi42
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TradingHelperOptions configures helpers built by NewTradingHelpers
+type TradingHelperOptions struct {
+	// Granularity of price steps as a percentage, as in NewTradingHelper
+	PercentIncrement float64
+	// When positive, the helpers share a ticker and depth response cache of
+	// their own with this TTL, so their prices may be up to CacheTTL old.
+	// The client's own market API is not affected.
+	CacheTTL time.Duration
+	// Sets BumpToMinNotional on every helper
+	BumpToMinNotional bool
+}
+
+// NewTradingHelpers creates TradingHelpers for many symbols at once. The
+// exchange info is loaded at most once and the tickers are fetched in
+// parallel, so startup costs one request per symbol instead of several.
+// Helpers are keyed by the requested symbol; symbols that failed are
+// reported in the returned error map instead.
+func NewTradingHelpers(client *Client, symbols []string, opts TradingHelperOptions) (map[string]*TradingHelper, map[string]error) {
+	helpers := make(map[string]*TradingHelper, len(symbols))
+	errs := make(map[string]error)
+
+	market := client.Market
+	if opts.CacheTTL > 0 {
+		market = NewMarketAPI(client)
+		market.SetCacheTTL(opts.CacheTTL)
+	}
+
+	client.infoMu.RLock()
+	loaded := len(client.ExchangeInfo) > 0
+	client.infoMu.RUnlock()
+
+	if !loaded {
+		if _, err := client.fetchExchangeInfo(); err != nil {
+			err = fmt.Errorf("failed to fetch exchange info: %v", err)
+			for _, symbol := range symbols {
+				errs[symbol] = err
+			}
+			return helpers, errs
+		}
+	}
+
+	tickers, tickerErrs := market.GetTickers(symbols)
+
+	for _, symbol := range symbols {
+		if err, ok := tickerErrs[symbol]; ok {
+			errs[symbol] = fmt.Errorf("failed to get current price: %v", err)
+			continue
+		}
+
+		contractInfo, err := client.GetContractInfo(symbol)
+		if err != nil {
+			errs[symbol] = err
+			continue
+		}
+
+		lastPrice, err := strconv.ParseFloat(tickers[symbol].LastPrice, 64)
+		if err != nil {
+			errs[symbol] = fmt.Errorf("could not convert price to float: %v", err)
+			continue
+		}
+
+		helper := &TradingHelper{
+			PercentIncrement:  opts.PercentIncrement,
+			BumpToMinNotional: opts.BumpToMinNotional,
+			client:            client,
+			market:            market,
+		}
+		helper.applyContract(contractInfo)
+		helper.setLastPrice(lastPrice)
+		helpers[symbol] = helper
+	}
+
+	return helpers, errs
+}
@@ -60,6 +60,53 @@ func (api *UserDataAPI) GetTradeHistory(params DataQueryParams) ([]TradeHistoryI
 	return result, nil
 }
 
+// tradeHistoryRange fetches every trade in the time range of params, paging
+// forward in ascending order until a short page is returned
+func (api *UserDataAPI) tradeHistoryRange(params DataQueryParams) ([]TradeHistoryItem, error) {
+	if params.PageSize <= 0 {
+		params.PageSize = 500
+	}
+	params.SortOrder = "ASC"
+
+	var trades []TradeHistoryItem
+	// Trades already collected at the cursor timestamp, since pages overlap
+	// at their boundary
+	seen := make(map[int]bool)
+	for {
+		page, err := api.GetTradeHistory(params)
+		if err != nil {
+			return trades, err
+		}
+
+		next := params.StartTimestamp
+		added := 0
+		for _, trade := range page {
+			if seen[trade.ID] {
+				continue
+			}
+			if t, err := trade.ParsedTime(); err == nil && t.UnixMilli() > next {
+				next = t.UnixMilli()
+				clear(seen)
+			}
+			seen[trade.ID] = true
+			added++
+			trades = append(trades, trade)
+		}
+
+		if len(page) < params.PageSize {
+			return trades, nil
+		}
+		// A full page sharing one timestamp would repeat forever
+		if added == 0 {
+			next++
+		}
+		if params.EndTimestamp > 0 && next > params.EndTimestamp {
+			return trades, nil
+		}
+		params.StartTimestamp = next
+	}
+}
+
 // TransactionHistoryParams extends DataQueryParams with additional fields
 type TransactionHistoryParams struct {
 	DataQueryParams
@@ -0,0 +1,359 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// listenKeyKeepAliveInterval is how often the listen key is refreshed.
+// Listen keys expire after 60 minutes without an update.
+const listenKeyKeepAliveInterval = 10 * time.Minute
+
+// userStreamRetryDelay is how long to wait before redialing when the
+// transport does not reconnect by itself
+const userStreamRetryDelay = 5 * time.Second
+
+// maxRecentFilledOrders bounds how many filled orders are remembered for
+// trades that arrive after their orderFilled event
+const maxRecentFilledOrders = 256
+
+// UserStream delivers authenticated account events (orders, positions,
+// balances and trades) from the Pi42 user data stream
+type UserStream struct {
+	client *Client
+
+	// Connection to the authenticated stream
+	io streamTransport
+	// Manages creation, refresh and renewal of the listen key
+	keys *ListenKeyManager
+	// Listen key used for the current connection
+	listenKey string
+	// Whether the stream should stay connected
+	active bool
+	// Channel receiving every user event
+	events chan UserEvent
+	// Channel receiving executions, and the latest state of orders with fills pending
+	fills  chan Fill
+	orders map[string]OpenOrder
+	// Recently filled orders, oldest first, so late trades are still
+	// reported as completing their order
+	filled      map[string]OpenOrder
+	filledOrder []string
+	// Callbacks registered per event
+	handlers map[EventName][]func(UserEvent)
+	// Independent consumers with their own buffers
+	subscribers []*UserSubscription
+	// Mutex for thread-safe access to handlers and connection state
+	mu sync.RWMutex
+}
+
+// NewUserStream creates a new user data stream for the client
+func NewUserStream(client *Client) *UserStream {
+	us := &UserStream{
+		client:   client,
+		keys:     NewListenKeyManager(client),
+		events:   make(chan UserEvent, defaultChannelBufferSize),
+		fills:    make(chan Fill, defaultChannelBufferSize),
+		orders:   make(map[string]OpenOrder),
+		filled:   make(map[string]OpenOrder),
+		handlers: make(map[EventName][]func(UserEvent)),
+	}
+	us.keys.OnRenew(us.reconnect)
+	return us
+}
+
+// Events returns the channel receiving all user data events. Events are
+// dropped when the channel buffer is full.
+func (us *UserStream) Events() <-chan UserEvent {
+	return us.events
+}
+
+// Fills returns the channel receiving a typed Fill for every execution of
+// the account's orders. Fills are dropped when the channel buffer is full.
+func (us *UserStream) Fills() <-chan Fill {
+	return us.fills
+}
+
+// On registers a callback for a user data event
+func (us *UserStream) On(event EventName, handler func(UserEvent)) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.handlers[event] = append(us.handlers[event], handler)
+}
+
+// OnOrderUpdate registers a callback for all order lifecycle events
+func (us *UserStream) OnOrderUpdate(handler func(UserEvent, *OrderUpdateEvent)) {
+	for _, event := range []EventName{
+		UserEventNewOrder, UserEventUpdateOrder, UserEventOrderFilled,
+		UserEventOrderPartiallyFilled, UserEventOrderCancelled, UserEventOrderFailed,
+	} {
+		us.On(event, func(e UserEvent) {
+			if order, ok := e.Payload.(*OrderUpdateEvent); ok {
+				handler(e, order)
+			}
+		})
+	}
+}
+
+// OnPositionUpdate registers a callback for position open, update and close events
+func (us *UserStream) OnPositionUpdate(handler func(UserEvent, *PositionUpdateEvent)) {
+	for _, event := range []EventName{
+		UserEventNewPosition, UserEventUpdatePosition, UserEventClosePosition,
+	} {
+		us.On(event, func(e UserEvent) {
+			if position, ok := e.Payload.(*PositionUpdateEvent); ok {
+				handler(e, position)
+			}
+		})
+	}
+}
+
+// OnBalanceUpdate registers a callback for balance updates
+func (us *UserStream) OnBalanceUpdate(handler func(*BalanceUpdateEvent)) {
+	us.On(UserEventBalanceUpdate, func(e UserEvent) {
+		if balance, ok := e.Payload.(*BalanceUpdateEvent); ok {
+			handler(balance)
+		}
+	})
+}
+
+// OnSessionExpired registers a callback for listen key expiry
+func (us *UserStream) OnSessionExpired(handler func(*SessionExpiredEvent)) {
+	us.On(UserEventSessionExpired, func(e UserEvent) {
+		if expired, ok := e.Payload.(*SessionExpiredEvent); ok {
+			handler(expired)
+		}
+	})
+}
+
+// Connect creates a listen key, connects to the authenticated stream and
+// returns once connected. The listen key is kept alive in the background and
+// recreated when it expires, after which the stream reconnects with the new
+// key. When ctx is cancelled the connection is closed and the listen key deleted.
+func (us *UserStream) Connect(ctx context.Context) error {
+	listenKey, err := us.keys.Create()
+	if err != nil {
+		return err
+	}
+
+	connected := make(chan struct{})
+	var connectedOnce sync.Once
+
+	us.mu.Lock()
+	us.active = true
+	us.mu.Unlock()
+
+	us.dial(listenKey, func() {
+		connectedOnce.Do(func() { close(connected) })
+	})
+
+	go us.keys.Run(ctx)
+
+	// Close the connection and release the listen key once the context is done
+	go func() {
+		<-ctx.Done()
+
+		us.mu.Lock()
+		us.active = false
+		io := us.io
+		us.io = nil
+		us.mu.Unlock()
+
+		if io != nil {
+			io.Disconnect()
+		}
+		if err := us.keys.Close(); err != nil {
+			logger().Warnf("%v", err)
+		}
+	}()
+
+	select {
+	case <-connected:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ListenKeys returns the manager of the stream's listen key
+func (us *UserStream) ListenKeys() *ListenKeyManager {
+	return us.keys
+}
+
+// dial connects to the authenticated stream with a listen key, replacing
+// any previous connection. onConnect is invoked on every successful connect.
+func (us *UserStream) dial(listenKey string, onConnect func()) {
+	serverURL := fmt.Sprintf("https://fawss-uds.pi42.com/auth-stream/%s", listenKey)
+	io, reconnects := newUserStreamTransport(serverURL)
+
+	io.On("connect", func(args ...any) {
+		logger().Infof("Connected to authenticated WebSocket stream")
+		if onConnect != nil {
+			onConnect()
+		}
+	})
+
+	io.On("connect_error", func(args ...any) {
+		logger().Warnf("User stream connection error: %v", args)
+		if !reconnects {
+			us.redial(io)
+		}
+	})
+
+	io.On("disconnect", func(args ...any) {
+		logger().Warnf("Disconnected from user stream: %v", args)
+		if !reconnects && (len(args) == 0 || args[0] != "io client disconnect") {
+			us.redial(io)
+		}
+	})
+
+	for _, event := range userStreamEvents {
+		io.On(event, us.createEventHandler(event))
+	}
+
+	us.mu.Lock()
+	previous := us.io
+	us.io = io
+	us.listenKey = listenKey
+	us.mu.Unlock()
+
+	if previous != nil {
+		previous.Disconnect()
+	}
+	io.Connect()
+}
+
+// redial connects io again after userStreamRetryDelay, unless the stream
+// was shut down or moved to another connection in the meantime
+func (us *UserStream) redial(io streamTransport) {
+	time.AfterFunc(userStreamRetryDelay, func() {
+		us.mu.RLock()
+		current := us.active && us.io == io
+		us.mu.RUnlock()
+
+		if current {
+			io.Connect()
+		}
+	})
+}
+
+// reconnect dials the stream again with a renewed listen key, unless the
+// stream has been shut down
+func (us *UserStream) reconnect(listenKey string) {
+	us.mu.RLock()
+	active := us.active
+	us.mu.RUnlock()
+
+	if !active {
+		return
+	}
+	logger().Infof("Reconnecting user stream with renewed listen key")
+	us.dial(listenKey, nil)
+}
+
+// createEventHandler returns a socket handler that decodes and dispatches an event
+func (us *UserStream) createEventHandler(event EventName) func(...any) {
+	return func(data ...any) {
+		userEvent := UserEvent{
+			Event:   event,
+			Data:    data,
+			Payload: decodeUserPayload(event, data),
+		}
+
+		us.mu.RLock()
+		handlers := us.handlers[event]
+		us.mu.RUnlock()
+
+		// The server expired the listen key; create a new one and reconnect
+		if event == UserEventSessionExpired {
+			go func() {
+				if _, err := us.keys.Renew(); err != nil {
+					logger().Warnf("Could not renew expired listen key: %v", err)
+				}
+			}()
+		}
+
+		us.trackFill(userEvent)
+		us.broadcast(userEvent)
+
+		for _, handler := range handlers {
+			handler(userEvent)
+		}
+
+		select {
+		case us.events <- userEvent:
+			// Message sent successfully
+		default:
+			logger().Warnf("User event channel full; dropping %s event", event)
+		}
+	}
+}
+
+// rememberFilled records a filled order, evicting the oldest once
+// maxRecentFilledOrders are remembered. The caller must hold mu.
+func (us *UserStream) rememberFilled(order OpenOrder) {
+	if _, exists := us.filled[order.ClientOrderID]; !exists {
+		us.filledOrder = append(us.filledOrder, order.ClientOrderID)
+	}
+	us.filled[order.ClientOrderID] = order
+
+	if len(us.filledOrder) > maxRecentFilledOrders {
+		delete(us.filled, us.filledOrder[0])
+		us.filledOrder = us.filledOrder[1:]
+	}
+}
+
+// trackFill records order progress from order events and emits a Fill for
+// every newTrade event
+func (us *UserStream) trackFill(e UserEvent) {
+	switch payload := e.Payload.(type) {
+	case *OrderUpdateEvent:
+		us.mu.Lock()
+		defer us.mu.Unlock()
+
+		order := payload.OpenOrder
+		if e.Event == UserEventOrderFilled {
+			order.Status = string(OrderStatusFilled)
+		}
+		switch e.Event {
+		case UserEventNewOrder, UserEventUpdateOrder:
+			// Track the order from the start, so its first trade carries
+			// the order amount even before any partial fill event
+			us.orders[order.ClientOrderID] = order
+		case UserEventOrderPartiallyFilled:
+			if order.OrderAmount > 0 && order.FilledAmount >= order.OrderAmount {
+				delete(us.orders, order.ClientOrderID)
+			} else {
+				us.orders[order.ClientOrderID] = order
+			}
+		case UserEventOrderFilled:
+			// Terminal event: stop tracking the order, remembering it in a
+			// bounded list in case trades for it are still to come
+			delete(us.orders, order.ClientOrderID)
+			us.rememberFilled(order)
+		case UserEventOrderCancelled, UserEventOrderFailed:
+			delete(us.orders, order.ClientOrderID)
+		}
+	case *TradeUpdateEvent:
+		us.mu.Lock()
+		var fill Fill
+		if order, ok := us.orders[payload.ClientOrderID]; ok {
+			fill = newFill(payload.TradeHistoryItem, &order)
+		} else if order, ok := us.filled[payload.ClientOrderID]; ok {
+			fill = newFill(payload.TradeHistoryItem, &order)
+		} else {
+			fill = newFill(payload.TradeHistoryItem, nil)
+		}
+		us.mu.Unlock()
+
+		select {
+		case us.fills <- fill:
+			// Fill sent successfully
+		default:
+			logger().Warnf("Fill channel full; dropping fill for order %s", fill.ClientOrderID)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package pi42
+
+import (
+	"slices"
+	"sync"
+)
+
+// UserSubscription is an independent consumer of user data events with its
+// own buffer. A slow subscriber drops its own events without affecting the
+// others or the Events channel.
+type UserSubscription struct {
+	stream *UserStream
+	// Events delivered to the subscription; all events if empty
+	events []EventName
+	ch     chan UserEvent
+
+	dropped uint64
+	closed  bool
+	mu      sync.Mutex
+}
+
+// Subscribe adds a subscriber receiving the given events, or every event if
+// none are given, on a channel buffered to bufferSize. Events are dropped
+// when the buffer is full.
+func (us *UserStream) Subscribe(bufferSize int, events ...EventName) *UserSubscription {
+	sub := &UserSubscription{
+		stream: us,
+		events: events,
+		ch:     make(chan UserEvent, bufferSize),
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.subscribers = append(us.subscribers, sub)
+	return sub
+}
+
+// C returns the channel receiving the subscription's events. It is closed by Close.
+func (s *UserSubscription) C() <-chan UserEvent {
+	return s.ch
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (s *UserSubscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dropped
+}
+
+// Close stops delivery to the subscription and closes its channel
+func (s *UserSubscription) Close() {
+	s.stream.mu.Lock()
+	// Copy so broadcasts in progress keep a consistent list
+	s.stream.subscribers = slices.DeleteFunc(slices.Clone(s.stream.subscribers), func(sub *UserSubscription) bool {
+		return sub == s
+	})
+	s.stream.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// send delivers an event if the subscription wants it
+func (s *UserSubscription) send(e UserEvent) {
+	if len(s.events) > 0 && !slices.Contains(s.events, e.Event) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- e:
+	default:
+		s.dropped++
+		// Log the first drop and then periodically to avoid flooding the log
+		if s.dropped == 1 || s.dropped%1000 == 0 {
+			logger().Warnf("User subscription buffer full; %d events dropped", s.dropped)
+		}
+	}
+}
+
+// broadcast delivers an event to every subscriber
+func (us *UserStream) broadcast(e UserEvent) {
+	us.mu.RLock()
+	subscribers := us.subscribers
+	us.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.send(e)
+	}
+}
@@ -0,0 +1,147 @@
+package pi42
+
+// User data stream event names
+const (
+	UserEventNewPosition          EventName = "newPosition"
+	UserEventUpdatePosition       EventName = "updatePosition"
+	UserEventClosePosition        EventName = "closePosition"
+	UserEventNewOrder             EventName = "newOrder"
+	UserEventUpdateOrder          EventName = "updateOrder"
+	UserEventOrderFilled          EventName = "orderFilled"
+	UserEventOrderPartiallyFilled EventName = "orderPartiallyFilled"
+	UserEventOrderCancelled       EventName = "orderCancelled"
+	UserEventOrderFailed          EventName = "orderFailed"
+	UserEventBalanceUpdate        EventName = "balanceUpdate"
+	UserEventNewTrade             EventName = "newTrade"
+	UserEventSessionExpired       EventName = "sessionExpired"
+)
+
+// userStreamEvents lists all events delivered by the user data stream
+var userStreamEvents = []EventName{
+	UserEventNewPosition,
+	UserEventUpdatePosition,
+	UserEventClosePosition,
+	UserEventNewOrder,
+	UserEventUpdateOrder,
+	UserEventOrderFilled,
+	UserEventOrderPartiallyFilled,
+	UserEventOrderCancelled,
+	UserEventOrderFailed,
+	UserEventBalanceUpdate,
+	UserEventNewTrade,
+	UserEventSessionExpired,
+}
+
+// UserEvent represents an event received on the user data stream
+type UserEvent struct {
+	// The event name (like orderFilled, balanceUpdate)
+	Event EventName
+	// The data received from the WebSocket
+	Data []any
+	// The decoded payload: *OrderUpdateEvent, *PositionUpdateEvent,
+	// *BalanceUpdateEvent, *TradeUpdateEvent or *SessionExpiredEvent.
+	// Nil if the payload could not be decoded.
+	Payload any
+}
+
+// OrderUpdateEvent represents an order lifecycle event (new, filled,
+// partially filled, cancelled, failed or updated)
+type OrderUpdateEvent struct {
+	OpenOrder
+}
+
+// PositionUpdateEvent represents a position being opened, updated or closed
+type PositionUpdateEvent struct {
+	PositionResponse
+}
+
+// BalanceUpdateEvent represents a change of the futures wallet balance
+type BalanceUpdateEvent struct {
+	FuturesWalletResponse
+}
+
+// TradeUpdateEvent represents a trade executed for the account
+type TradeUpdateEvent struct {
+	TradeHistoryItem
+}
+
+// SessionExpiredEvent signals that the listen key expired and the stream closed
+type SessionExpiredEvent struct {
+	Message string `json:"message"`
+}
+
+// decodeUserPayload decodes a user data stream payload into its typed structure
+func decodeUserPayload(event EventName, data []any) any {
+	var payload any
+	switch event {
+	case UserEventNewOrder, UserEventUpdateOrder, UserEventOrderFilled,
+		UserEventOrderPartiallyFilled, UserEventOrderCancelled, UserEventOrderFailed:
+		payload = &OrderUpdateEvent{}
+	case UserEventNewPosition, UserEventUpdatePosition, UserEventClosePosition:
+		payload = &PositionUpdateEvent{}
+	case UserEventBalanceUpdate:
+		payload = &BalanceUpdateEvent{}
+	case UserEventNewTrade:
+		payload = &TradeUpdateEvent{}
+	case UserEventSessionExpired:
+		// The payload may be empty, a plain message or an object
+		if len(data) == 0 {
+			return &SessionExpiredEvent{}
+		}
+		if message, ok := data[0].(string); ok {
+			return &SessionExpiredEvent{Message: message}
+		}
+		payload = &SessionExpiredEvent{}
+	default:
+		return nil
+	}
+
+	if err := decodeEventPayload(data, payload); err != nil {
+		return nil
+	}
+	return payload
+}
+
+// Fill describes an execution of one of the account's orders. It is built
+// from a newTrade event and annotated with the order's progress from the
+// order events received so far.
+type Fill struct {
+	ClientOrderID string
+	TradeID       int
+	Symbol        string
+	Side          string
+	Price         float64
+	Quantity      float64
+	Fee           float64
+	RealizedPnl   float64
+	Role          string // Maker or taker
+	Time          string
+	// Cumulative filled amount and total amount of the order, zero if no
+	// order event was received for it
+	OrderFilled float64
+	OrderAmount float64
+	// Whether the order was completely filled
+	Complete bool
+}
+
+// newFill builds a fill from a trade and the latest known state of its order
+func newFill(trade TradeHistoryItem, order *OpenOrder) Fill {
+	fill := Fill{
+		ClientOrderID: trade.ClientOrderID,
+		TradeID:       trade.ID,
+		Symbol:        trade.Symbol,
+		Side:          trade.Side,
+		Price:         trade.Price,
+		Quantity:      trade.Quantity,
+		Fee:           trade.Fee,
+		RealizedPnl:   trade.RealizedProfit,
+		Role:          trade.Role,
+		Time:          trade.Time,
+	}
+	if order != nil {
+		fill.OrderFilled = order.FilledAmount
+		fill.OrderAmount = order.OrderAmount
+		fill.Complete = order.Status == string(OrderStatusFilled)
+	}
+	return fill
+}
@@ -0,0 +1,120 @@
+package pi42
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// defaultValueAreaPercent is the share of volume covered by the value area
+const defaultValueAreaPercent = 70.0
+
+// VolumeBucket holds the traded volume within a price range
+type VolumeBucket struct {
+	Price      float64 // Lower bound of the bucket
+	Volume     float64 // Total traded quantity
+	BuyVolume  float64 // Quantity traded with a buying taker
+	SellVolume float64 // Quantity traded with a selling taker
+}
+
+// VolumeProfile is a price-bucketed distribution of traded volume
+type VolumeProfile struct {
+	Start          time.Time
+	End            time.Time
+	BucketSize     float64
+	Buckets        []VolumeBucket // Ordered by ascending price
+	TotalVolume    float64
+	PointOfControl float64 // Price of the bucket with the highest volume
+	ValueAreaHigh  float64 // Upper bound of the value area
+	ValueAreaLow   float64 // Lower bound of the value area
+}
+
+// BuildVolumeProfile aggregates trades executed within [start, end) into price
+// buckets of bucketSize and computes the point of control and the value area
+// holding 70% of the volume
+func BuildVolumeProfile(trades []Trade, bucketSize float64, start, end time.Time) (*VolumeProfile, error) {
+	if bucketSize <= 0 {
+		return nil, fmt.Errorf("bucket size must be greater than 0")
+	}
+
+	volumes := make(map[int64]*VolumeBucket)
+	profile := &VolumeProfile{Start: start, End: end, BucketSize: bucketSize}
+
+	for _, trade := range trades {
+		if trade.Time.Before(start) || !trade.Time.Before(end) {
+			continue
+		}
+
+		index := int64(math.Floor(trade.Price / bucketSize))
+		bucket, ok := volumes[index]
+		if !ok {
+			bucket = &VolumeBucket{Price: float64(index) * bucketSize}
+			volumes[index] = bucket
+		}
+		bucket.Volume += trade.Quantity
+		if trade.Side == OrderSideSell {
+			bucket.SellVolume += trade.Quantity
+		} else {
+			bucket.BuyVolume += trade.Quantity
+		}
+		profile.TotalVolume += trade.Quantity
+	}
+
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("no trades in the requested window")
+	}
+
+	for _, bucket := range volumes {
+		profile.Buckets = append(profile.Buckets, *bucket)
+	}
+	sort.Slice(profile.Buckets, func(i, j int) bool {
+		return profile.Buckets[i].Price < profile.Buckets[j].Price
+	})
+
+	poc := 0
+	for i, bucket := range profile.Buckets {
+		if bucket.Volume > profile.Buckets[poc].Volume {
+			poc = i
+		}
+	}
+	profile.PointOfControl = profile.Buckets[poc].Price
+
+	// Expand from the point of control towards the larger neighbouring bucket
+	// until the value area holds the target share of volume
+	low, high := poc, poc
+	covered := profile.Buckets[poc].Volume
+	target := profile.TotalVolume * defaultValueAreaPercent / 100
+	for covered < target && (low > 0 || high < len(profile.Buckets)-1) {
+		below, above := -1.0, -1.0
+		if low > 0 {
+			below = profile.Buckets[low-1].Volume
+		}
+		if high < len(profile.Buckets)-1 {
+			above = profile.Buckets[high+1].Volume
+		}
+		if above >= below {
+			high++
+			covered += above
+		} else {
+			low--
+			covered += below
+		}
+	}
+	profile.ValueAreaLow = profile.Buckets[low].Price
+	profile.ValueAreaHigh = profile.Buckets[high].Price + bucketSize
+
+	return profile, nil
+}
+
+// GetVolumeProfile builds a volume profile from the aggregated trades of the
+// last window for a trading pair
+func (api *MarketAPI) GetVolumeProfile(contractPair string, bucketSize float64, window time.Duration) (*VolumeProfile, error) {
+	trades, err := api.GetAggTradeList(contractPair)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	return BuildVolumeProfile(trades, bucketSize, end.Add(-window), end.Add(time.Millisecond))
+}
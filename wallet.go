@@ -64,3 +64,39 @@ func (api *WalletAPI) FundingWalletDetails(marginAsset string) (*FundingWalletRe
 
 	return &result, nil
 }
+
+// Transfer moves an amount of an asset between the funding and futures wallets
+func (api *WalletAPI) Transfer(from, to WalletType, asset string, amount float64) (*TransferResponse, error) {
+	if from == to {
+		return nil, fmt.Errorf("cannot transfer from the %s wallet to itself", from)
+	}
+	for _, wallet := range []WalletType{from, to} {
+		if wallet != WalletFunding && wallet != WalletFutures {
+			return nil, fmt.Errorf("invalid wallet %q (expected %s or %s)", wallet, WalletFunding, WalletFutures)
+		}
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("transfer amount must be greater than 0")
+	}
+
+	endpoint := "/v1/wallet/transfer"
+
+	params := map[string]interface{}{
+		"fromWallet": from,
+		"toWallet":   to,
+		"asset":      asset,
+		"amount":     amount,
+	}
+
+	data, err := api.client.Post(endpoint, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result TransferResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error parsing response: %v", err)
+	}
+
+	return &result, nil
+}
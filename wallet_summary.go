@@ -0,0 +1,155 @@
+package pi42
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// AssetBalances holds the futures and funding wallet balances of one
+// margin asset
+type AssetBalances struct {
+	MarginAsset string
+	Futures     FuturesWalletBalances
+	Funding     FundingWalletBalances
+	// Futures plus funding wallet balance in the margin asset
+	Total float64
+	// Total converted to the summary's reference asset
+	Value float64
+}
+
+// WalletSummary consolidates the wallets of every margin asset. Totals are
+// converted to ReferenceAsset with the exchange margin conversion rates.
+type WalletSummary struct {
+	ReferenceAsset string
+	// Balances per margin asset, sorted by asset
+	Assets         []AssetBalances
+	FuturesBalance float64
+	FundingBalance float64
+	UnrealisedPnl  float64
+	Total          float64
+}
+
+// MarginAssets returns the margin assets supported by any loaded contract,
+// sorted by name
+func (c *Client) MarginAssets() []string {
+	c.infoMu.RLock()
+	exchangeInfo := c.ExchangeInfo
+	c.infoMu.RUnlock()
+
+	var assets []string
+	for _, contract := range exchangeInfo {
+		for _, asset := range contract.MarginAssets {
+			asset = strings.ToUpper(asset)
+			if !slices.Contains(assets, asset) {
+				assets = append(assets, asset)
+			}
+		}
+	}
+	slices.Sort(assets)
+	return assets
+}
+
+// GetAllBalances queries the futures and funding wallets of every supported
+// margin asset concurrently and consolidates them, valued in referenceAsset
+func (api *WalletAPI) GetAllBalances(referenceAsset string) (*WalletSummary, error) {
+	balances, err := api.fetchAllBalances()
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeWallets(balances, api.client.Converter(), referenceAsset)
+}
+
+// fetchAllBalances fetches the wallets of every supported margin asset
+// concurrently, failing if any request fails
+func (api *WalletAPI) fetchAllBalances() ([]AssetBalances, error) {
+	assets := api.client.MarginAssets()
+	if len(assets) == 0 {
+		assets = []string{"INR"}
+	}
+
+	balances := make([]AssetBalances, len(assets))
+	errs := make([]error, len(assets))
+
+	var wg sync.WaitGroup
+	for i, asset := range assets {
+		wg.Add(1)
+		go func(i int, asset string) {
+			defer wg.Done()
+			balances[i], errs[i] = api.assetBalances(asset)
+		}(i, asset)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s wallets: %v", assets[i], err)
+		}
+	}
+	return balances, nil
+}
+
+// assetBalances fetches both wallets of a margin asset concurrently
+func (api *WalletAPI) assetBalances(asset string) (AssetBalances, error) {
+	var (
+		futures                *FuturesWalletResponse
+		funding                *FundingWalletResponse
+		futuresErr, fundingErr error
+		wg                     sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		futures, futuresErr = api.FuturesWalletDetails(asset)
+	}()
+	go func() {
+		defer wg.Done()
+		funding, fundingErr = api.FundingWalletDetails(asset)
+	}()
+	wg.Wait()
+
+	if futuresErr != nil {
+		return AssetBalances{}, futuresErr
+	}
+	if fundingErr != nil {
+		return AssetBalances{}, fundingErr
+	}
+
+	result := AssetBalances{MarginAsset: asset}
+	var err error
+	if result.Futures, err = futures.Balances(); err != nil {
+		return AssetBalances{}, err
+	}
+	if result.Funding, err = funding.Balances(); err != nil {
+		return AssetBalances{}, err
+	}
+	return result, nil
+}
+
+// SummarizeWallets consolidates per-asset balances into a summary valued in
+// referenceAsset
+func SummarizeWallets(balances []AssetBalances, converter *Converter, referenceAsset string) (*WalletSummary, error) {
+	summary := &WalletSummary{ReferenceAsset: strings.ToUpper(referenceAsset)}
+
+	for _, asset := range balances {
+		rate, err := converter.Rate(asset.MarginAsset, summary.ReferenceAsset)
+		if err != nil {
+			return nil, fmt.Errorf("error valuing %s wallets: %v", asset.MarginAsset, err)
+		}
+
+		asset.Total = asset.Futures.WalletBalance + asset.Funding.WalletBalance
+		asset.Value = asset.Total * rate
+
+		summary.FuturesBalance += asset.Futures.WalletBalance * rate
+		summary.FundingBalance += asset.Funding.WalletBalance * rate
+		summary.UnrealisedPnl += asset.Futures.UnrealisedPnl() * rate
+		summary.Total += asset.Value
+		summary.Assets = append(summary.Assets, asset)
+	}
+
+	slices.SortFunc(summary.Assets, func(a, b AssetBalances) int {
+		return strings.Compare(a.MarginAsset, b.MarginAsset)
+	})
+	return summary, nil
+}
@@ -1,5 +1,10 @@
 package pi42
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // FuturesWalletResponse represents the futures wallet information
 type FuturesWalletResponse struct {
 	InrBalance             string `json:"inrBalance"`
@@ -26,3 +31,112 @@ type FundingWalletResponse struct {
 	LockedBalance       string `json:"lockedBalance"`
 	MarginAsset         string `json:"marginAsset"`
 }
+
+// WalletType identifies one of the account's wallets
+type WalletType string
+
+// Wallets funds can be transferred between
+const (
+	WalletFunding WalletType = "FUNDING"
+	WalletFutures WalletType = "FUTURES"
+)
+
+// TransferResponse represents the result of a transfer between wallets
+type TransferResponse struct {
+	TransferID string     `json:"transferId"`
+	Status     string     `json:"status"`
+	Asset      string     `json:"asset"`
+	Amount     float64    `json:"amount"`
+	From       WalletType `json:"from"`
+	To         WalletType `json:"to"`
+	Time       string     `json:"time"`
+}
+
+// FuturesWalletBalances holds the balances of a futures wallet as numbers
+type FuturesWalletBalances struct {
+	InrBalance             float64
+	WalletBalance          float64
+	WithdrawableBalance    float64
+	MaintenanceMargin      float64
+	UnrealisedPnlCross     float64
+	UnrealisedPnlIsolated  float64
+	MaxWithdrawableBalance float64
+	LockedBalance          float64
+	MarginBalance          float64
+	PnlPercentCross        float64
+	PnlPercentIsolated     float64
+	LockedBalanceCross     float64
+	LockedBalanceIsolated  float64
+	MarginAsset            string
+}
+
+// UnrealisedPnl returns the unrealized PnL of cross and isolated positions
+func (b FuturesWalletBalances) UnrealisedPnl() float64 {
+	return b.UnrealisedPnlCross + b.UnrealisedPnlIsolated
+}
+
+// Balances parses the wallet's balances. Empty values are treated as zero.
+func (w FuturesWalletResponse) Balances() (FuturesWalletBalances, error) {
+	b := FuturesWalletBalances{MarginAsset: w.MarginAsset}
+	p := balanceParser{}
+	b.InrBalance = p.parse("inrBalance", w.InrBalance)
+	b.WalletBalance = p.parse("walletBalance", w.WalletBalance)
+	b.WithdrawableBalance = p.parse("withdrawableBalance", w.WithdrawableBalance)
+	b.MaintenanceMargin = p.parse("maintenanceMargin", w.MaintenanceMargin)
+	b.UnrealisedPnlCross = p.parse("unrealisedPnlCross", w.UnrealisedPnlCross)
+	b.UnrealisedPnlIsolated = p.parse("unrealisedPnlIsolated", w.UnrealisedPnlIsolated)
+	b.MaxWithdrawableBalance = p.parse("maxWithdrawableBalance", w.MaxWithdrawableBalance)
+	b.LockedBalance = p.parse("lockedBalance", w.LockedBalance)
+	b.MarginBalance = p.parse("marginBalance", w.MarginBalance)
+	b.PnlPercentCross = p.parse("pnlPercentCross", w.PnlPercentCross)
+	b.PnlPercentIsolated = p.parse("pnlPercentIsolated", w.PnlPercentIsolated)
+	b.LockedBalanceCross = p.parse("lockedBalanceCross", w.LockedBalanceCross)
+	b.LockedBalanceIsolated = p.parse("lockedBalanceIsolated", w.LockedBalanceIsolated)
+	return b, p.err
+}
+
+// FundingWalletBalances holds the balances of a funding wallet as numbers
+type FundingWalletBalances struct {
+	InrBalance          float64
+	WalletBalance       float64
+	WithdrawableBalance float64
+	LockedBalance       float64
+	MarginAsset         string
+}
+
+// Balances parses the wallet's balances. Empty values are treated as zero.
+func (w FundingWalletResponse) Balances() (FundingWalletBalances, error) {
+	b := FundingWalletBalances{MarginAsset: w.MarginAsset}
+	p := balanceParser{}
+	b.InrBalance = p.parse("inrBalance", w.InrBalance)
+	b.WalletBalance = p.parse("walletBalance", w.WalletBalance)
+	b.WithdrawableBalance = p.parse("withdrawableBalance", w.WithdrawableBalance)
+	b.LockedBalance = p.parse("lockedBalance", w.LockedBalance)
+	return b, p.err
+}
+
+// balanceParser parses balance fields, keeping the first error
+type balanceParser struct {
+	err error
+}
+
+// parse parses a balance field, returning zero on error
+func (p *balanceParser) parse(field, value string) float64 {
+	parsed, err := parseBalance(field, value)
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+	return parsed
+}
+
+// parseBalance parses a balance field, treating an empty value as zero
+func parseBalance(field, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", field, value, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,70 @@
+package pi42
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PortfolioValue is the value of all wallets in a single reference asset,
+// converted with the exchange margin conversion rates and rounded to the
+// reference asset's precision
+type PortfolioValue struct {
+	ReferenceAsset string
+	FuturesBalance float64
+	FundingBalance float64
+	UnrealisedPnl  float64
+	Withdrawable   float64 // Withdrawable from the futures and funding wallets
+	Locked         float64 // Locked in the futures and funding wallets
+	// Futures and funding balances plus unrealized PnL
+	Equity float64
+	// Equity per margin asset, in the reference asset
+	Assets map[string]float64
+}
+
+// GetPortfolioValue fetches the wallets of every supported margin asset and
+// values them in referenceAsset
+func (api *WalletAPI) GetPortfolioValue(referenceAsset string) (*PortfolioValue, error) {
+	balances, err := api.fetchAllBalances()
+	if err != nil {
+		return nil, err
+	}
+	return ValuePortfolio(balances, api.client.Converter(), referenceAsset)
+}
+
+// ValuePortfolio converts wallet balances of several margin assets into
+// referenceAsset
+func ValuePortfolio(balances []AssetBalances, converter *Converter, referenceAsset string) (*PortfolioValue, error) {
+	value := &PortfolioValue{
+		ReferenceAsset: strings.ToUpper(referenceAsset),
+		Assets:         make(map[string]float64, len(balances)),
+	}
+
+	for _, asset := range balances {
+		rate, err := converter.Rate(asset.MarginAsset, value.ReferenceAsset)
+		if err != nil {
+			return nil, fmt.Errorf("error valuing %s wallets: %v", asset.MarginAsset, err)
+		}
+
+		futures, funding := asset.Futures, asset.Funding
+		equity := (futures.WalletBalance + funding.WalletBalance + futures.UnrealisedPnl()) * rate
+
+		value.FuturesBalance += futures.WalletBalance * rate
+		value.FundingBalance += funding.WalletBalance * rate
+		value.UnrealisedPnl += futures.UnrealisedPnl() * rate
+		value.Withdrawable += (futures.WithdrawableBalance + funding.WithdrawableBalance) * rate
+		value.Locked += (futures.LockedBalance + funding.LockedBalance) * rate
+		value.Equity += equity
+		value.Assets[strings.ToUpper(asset.MarginAsset)] += equity
+	}
+
+	for _, amount := range []*float64{
+		&value.FuturesBalance, &value.FundingBalance, &value.UnrealisedPnl,
+		&value.Withdrawable, &value.Locked, &value.Equity,
+	} {
+		*amount = converter.Round(*amount, value.ReferenceAsset)
+	}
+	for asset, amount := range value.Assets {
+		value.Assets[asset] = converter.Round(amount, value.ReferenceAsset)
+	}
+	return value, nil
+}
@@ -1,47 +1,117 @@
 package pi42
 
 import (
-	"os"
+	"context"
+	"encoding/json"
+	"fmt"
 	"os/signal"
+	"slices"
 	"sync"
 	"syscall"
-
-	"github.com/zishang520/engine.io-client-go/transports"
-	"github.com/zishang520/engine.io/v2/types"
-	"github.com/zishang520/engine.io/v2/utils"
-	"github.com/zishang520/socket.io-client-go/socket"
+	"time"
 )
 
+// EventName identifies a stream event, e.g. "depthUpdate" or "orderFilled"
+type EventName string
+
 // EventData represents data received from a WebSocket event
 type EventData struct {
 	// The event name (like depthUpdate, markPriceUpdate)
-	Event types.EventName
-	// The specific topic for this event (like btcinr@depth_0.1)
+	Event EventName
+	// The specific topic for this event (like btcinr@depth_0.1). Empty for
+	// events that are not tied to a symbol, such as tickerArr.
 	Topic string
 	// The data received from the WebSocket
 	Data []any
+	// The decoded payload for known events: *DepthUpdateEvent, *MarkPriceEvent,
+	// *KlineEvent, *TickerEvent, *AggTradeEvent or []Ticker. Nil otherwise.
+	Payload any
 }
 
 // SocketClient is a client for WebSocket connections
 type SocketClient struct {
-	// Socket client instance
-	io *socket.Socket
-	// Manager instance for handling connections
-	manager *socket.Manager
+	// Connection to the stream server
+	io streamTransport
+	// Transport implementation used by Connect
+	transport Transport
+	// Whether permessage-deflate compression is negotiated
+	compression bool
 	// List of events to subscribe to
-	events []types.EventName
-	// List of topics to subscribe to
+	events []EventName
+	// List of topics to subscribe to, guarded by channelMutex
 	topics []string
+	// Serializes subscription changes with the subscribe and unsubscribe
+	// messages they send, so messages reach the server in the same order
+	subscriptionMutex sync.Mutex
 	// Channels for events, mapped by event name
-	eventChannels map[types.EventName]chan EventData
+	eventChannels map[EventName]chan EventData
+	// Dedicated channels for individual topics, mapped by topic
+	topicChannels map[string]chan EventData
+	// Channel receiving all events in arrival order, created by Events
+	multiplexed       chan EventData
+	multiplexedConfig *ChannelConfig
+	// Whether events delivered to a topic channel are also sent to the event channel
+	eventFanIn bool
+	// Buffering configuration per event and per topic
+	eventConfigs map[EventName]ChannelConfig
+	topicConfigs map[string]ChannelConfig
+	// Typed callbacks registered per topic
+	topicCallbacks map[string][]func(EventData)
+	// Middleware run on events before delivery, per event type and per topic
+	eventMiddleware map[EventName][]EventMiddleware
+	topicMiddleware map[string][]EventMiddleware
+	// Depth grouping per symbol used by OnDepth
+	depthGroupings map[string]string
+	// Market API used to load order book snapshots for SubscribeOrderBook
+	market *MarketAPI
+	// Message arrival statistics per topic and staleness detection settings
+	topicStats   map[string]*topicStats
+	staleTimeout time.Duration
+	onStale      func(topic string, lastReceived time.Time)
+	healthMutex  sync.Mutex
+	// Optional hook receiving incoming events before decoding
+	rawTap func(RawMessage)
+	// Message count and latency metrics per event type
+	metrics *metricsRecorder
+	// Server ping statistics and missed heartbeat detection
+	heartbeat heartbeatMonitor
+	// Number of dropped messages per event or topic
+	drops     map[string]uint64
+	dropMutex sync.Mutex
+	// Topics unsubscribed upstream until their consumers catch up
+	pausedTopics   map[string]bool
+	onSlowConsumer []func(topic string, paused bool)
+	pauseMutex     sync.Mutex
 	// Mutex for thread-safe access to channels
 	channelMutex sync.RWMutex
+	// OverflowBlock sends waiting outside channelMutex, per channel
+	pendingSends map[chan EventData]*pendingSends
+	pendingMutex sync.Mutex
+	// Optional worker pool used to dispatch events off the read goroutine
+	pool *workerPool
+	// Cancels the context of the current connection
+	cancel context.CancelFunc
+	// Whether Close has been called; channels are closed once set
+	closed    bool
+	closeOnce sync.Once
+	// Callback invoked with the restored topics after a reconnect
+	onResubscribe func(topics []string)
+	// Callbacks invoked when the server rejects a topic
+	subscriptionErrors []func(topic string, err error)
+	// Whether the first connection has been established, guarded by stateMutex
+	hasConnected bool
+	// Reconnect behavior, connection state and state change callbacks
+	reconnectPolicy   ReconnectPolicy
+	reconnectAttempts int
+	state             ConnectionState
+	stateCallbacks    []func(ConnectionState)
+	stateMutex        sync.Mutex
 }
 
 // NewSocketClient creates a new WebSocket client
 func NewSocketClient() *SocketClient {
-	ec := make(map[types.EventName]chan EventData)
-	for _, event := range []types.EventName{
+	ec := make(map[EventName]chan EventData)
+	for _, event := range []EventName{
 		"depthUpdate",
 		"markPriceUpdate",
 		"kline",
@@ -52,10 +122,10 @@ func NewSocketClient() *SocketClient {
 		"markPriceArr",
 		"allContractDetails",
 	} {
-		ec[event] = make(chan EventData) // Buffered channel for each event
+		ec[event] = make(chan EventData, defaultChannelBufferSize) // Buffered channel for each event
 	}
 	return &SocketClient{
-		events: []types.EventName{
+		events: []EventName{
 			"depthUpdate",
 			"markPriceUpdate",
 			"kline",
@@ -68,52 +138,174 @@ func NewSocketClient() *SocketClient {
 		},
 		topics:        []string{},
 		eventChannels: ec,
+		topicChannels: make(map[string]chan EventData),
+		transport:     defaultTransport,
+		eventFanIn:    true,
+		compression:   true,
+		eventConfigs:  make(map[EventName]ChannelConfig),
+		topicConfigs:  make(map[string]ChannelConfig),
+		drops:         make(map[string]uint64),
+		pausedTopics:  make(map[string]bool),
+
+		topicCallbacks:  make(map[string][]func(EventData)),
+		eventMiddleware: make(map[EventName][]EventMiddleware),
+		topicMiddleware: make(map[string][]EventMiddleware),
+		depthGroupings:  make(map[string]string),
+		topicStats:      make(map[string]*topicStats),
+		metrics:         newMetricsRecorder(),
+
+		reconnectPolicy: DefaultReconnectPolicy(),
 	}
 }
 
-// AddStream adds a new topic and corresponding event handler
-func (sc *SocketClient) AddStream(topic string, event types.EventName) {
+// AddStream adds a new topic and corresponding event handler. It is safe
+// for concurrent use.
+func (sc *SocketClient) AddStream(topic string, event EventName) {
+	sc.subscriptionMutex.Lock()
+	defer sc.subscriptionMutex.Unlock()
+
+	sc.channelMutex.Lock()
 	// Check if topic already exists
 	for _, t := range sc.topics {
 		if t == topic {
+			sc.channelMutex.Unlock()
 			return // Topic already exists
 		}
 	}
-
 	sc.topics = append(sc.topics, topic)
+	sc.channelMutex.Unlock()
 
 	// If already connected, subscribe to the new topic immediately
 	if sc.io != nil && sc.io.Connected() {
-		sc.io.Emit("subscribe", map[string][]string{
-			"params": {topic},
-		})
+		emitSubscribe(sc, []string{topic}, nil)
 	}
 }
 
-// RemoveStream removes a specific topic from the subscription list
+// RemoveStream removes a specific topic from the subscription list. It is
+// safe for concurrent use.
 func (sc *SocketClient) RemoveStream(topic string) {
+	sc.subscriptionMutex.Lock()
+	defer sc.subscriptionMutex.Unlock()
+
+	sc.channelMutex.Lock()
+	removed := false
 	// Find and remove the topic from the list
 	for i, t := range sc.topics {
 		if t == topic {
-			// Remove the topic using slice manipulation
 			sc.topics = append(sc.topics[:i], sc.topics[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	sc.channelMutex.Unlock()
+
+	if removed {
+		// If already connected, unsubscribe from the topic immediately
+		if sc.io != nil && sc.io.Connected() {
+			sc.io.Emit("unsubscribe", map[string][]string{
+				"params": {topic},
+			})
+			logger().Infof("Unsubscribed from topic: %s", topic)
+		}
+		return
+	}
 
-			// If already connected, unsubscribe from the topic immediately
-			if sc.io != nil && sc.io.Connected() {
-				sc.io.Emit("unsubscribe", map[string][]string{
-					"params": {topic},
-				})
-				utils.Log().Info("Unsubscribed from topic: %s", topic)
-			}
-			return
+	logger().Warnf("Topic not found for removal: %s", topic)
+}
+
+// Subscribe adds a topic and returns a channel that receives only that
+// topic's events, with EventData.Topic set. Subscribing to the same topic
+// again returns the existing channel.
+func (sc *SocketClient) Subscribe(topic string) (<-chan EventData, error) {
+	event, ok := TopicEvent(topic)
+	if !ok {
+		return nil, fmt.Errorf("unsupported topic: %s", topic)
+	}
+
+	sc.channelMutex.Lock()
+	ch, exists := sc.topicChannels[topic]
+	if !exists {
+		ch = make(chan EventData, sc.topicChannelConfig(topic).BufferSize)
+		sc.topicChannels[topic] = ch
+	}
+	sc.channelMutex.Unlock()
+
+	sc.AddStream(topic, event)
+	return ch, nil
+}
+
+// KlineOptions configures a kline subscription
+type KlineOptions struct {
+	// Deliver only closed candles, once per start time, suppressing
+	// intra-candle updates
+	ClosedOnly bool
+}
+
+// SubscribeKlines subscribes to a symbol's candles for an interval and
+// returns a channel of its events, each carrying a *KlineEvent payload.
+// With ClosedOnly set, the filter also applies to the kline event channel
+// and callbacks for the topic.
+func (sc *SocketClient) SubscribeKlines(symbol string, interval KlineInterval, options KlineOptions) (<-chan EventData, error) {
+	if err := interval.Validate(); err != nil {
+		return nil, err
+	}
+	topic := KlineTopic(symbol, interval)
+
+	if options.ClosedOnly {
+		sc.UseTopic(topic, closedKlineFilter())
+	}
+	return sc.Subscribe(topic)
+}
+
+// closedKlineFilter returns middleware passing each closed candle once
+func closedKlineFilter() EventMiddleware {
+	var lastStart int64
+	var mu sync.Mutex
+
+	return func(e EventData) (EventData, bool) {
+		kline, ok := e.Payload.(*KlineEvent)
+		if !ok || !kline.Kline.IsClosed {
+			return e, false
 		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if kline.Kline.StartTime <= lastStart {
+			return e, false // Already delivered
+		}
+		lastStart = kline.Kline.StartTime
+		return e, true
 	}
+}
 
-	utils.Log().Warning("Topic not found for removal: %s", topic)
+// Unsubscribe removes a topic, its callbacks and its dedicated channel, if any
+func (sc *SocketClient) Unsubscribe(topic string) {
+	sc.RemoveStream(topic)
+
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	delete(sc.topicCallbacks, topic)
+	delete(sc.topicMiddleware, topic)
+	if ch, exists := sc.topicChannels[topic]; exists {
+		delete(sc.topicChannels, topic)
+		sc.closeChannel(ch)
+	}
+}
+
+// SetEventFanIn controls whether events that were delivered to a topic
+// channel from Subscribe are also sent to the per-event channel returned by
+// GetEventChannel. Fan-in is enabled by default.
+func (sc *SocketClient) SetEventFanIn(enabled bool) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.eventFanIn = enabled
 }
 
 // GetEventChannel returns a channel for a specific event
-func (sc *SocketClient) GetEventChannel(event types.EventName) (chan EventData, bool) {
+func (sc *SocketClient) GetEventChannel(event EventName) (chan EventData, bool) {
 	sc.channelMutex.RLock()
 	defer sc.channelMutex.RUnlock()
 
@@ -121,111 +313,281 @@ func (sc *SocketClient) GetEventChannel(event types.EventName) (chan EventData,
 	return ch, exists
 }
 
-func (sc *SocketClient) Init() {
-	// Setup signal handling for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+// Connect establishes the WebSocket connection and returns once the client is
+// connected. The connection is closed when ctx is cancelled; signal handling
+// is left to the application. If ctx is cancelled before the connection is
+// established, Connect returns the context error.
+func (sc *SocketClient) Connect(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	sc.channelMutex.Lock()
+	sc.cancel = cancel
+	sc.channelMutex.Unlock()
+
+	connected := make(chan struct{})
+	var connectedOnce sync.Once
+	gaveUp := make(chan struct{})
+	var gaveUpOnce sync.Once
+
+	sc.stateMutex.Lock()
+	sc.hasConnected = false
+	sc.reconnectAttempts = 0
+	sc.stateMutex.Unlock()
+	sc.setState(StateConnecting)
 
-	opts := socket.DefaultOptions()
-	opts.SetTransports(types.NewSet(transports.Polling, transports.WebSocket))
+	sc.channelMutex.RLock()
+	transport := sc.transport
+	compression := sc.compression
+	sc.channelMutex.RUnlock()
 
-	// Updated server URL
-	manager := socket.NewManager("https://fawss.pi42.com/", opts)
-	sc.manager = manager
+	if transport == TransportSocketIO && !compression {
+		logger().Infof("Compression cannot be disabled on the socket.io transport; using the native transport")
+		transport = TransportNative
+	}
 
-	// Listening to manager events
-	sc.manager.On("error", func(errs ...any) {
-		utils.Log().Warning("Manager Error: %v", errs)
-	})
+	var io streamTransport
+	switch transport {
+	case TransportNative:
+		io = newNativeTransport("wss://fawss.pi42.com/socket.io/?EIO=4&transport=websocket", sc.heartbeat.ping, compression)
+	default:
+		var err error
+		if io, err = newSocketIOTransport("https://fawss.pi42.com/", sc.heartbeat.ping); err != nil {
+			sc.setState(StateDisconnected)
+			return err
+		}
+	}
+	sc.io = io
 
-	sc.manager.On("ping", func(...any) {
-		utils.Log().Warning("Manager Ping")
-	})
+	// Event handlers are registered once; they survive reconnects
+	setupEventHandlers(sc)
 
-	sc.manager.On("reconnect", func(...any) {
-		utils.Log().Warning("Manager Reconnected")
-	})
+	sc.io.On("connect", func(args ...any) {
+		logger().Infof("Connected to WebSocket server, ID: %v", io.ID())
+
+		// Subscribe to topics after every (re)connect, since the server
+		// forgets subscriptions when the connection drops
+		topics := subscribeToTopics(sc)
+		sc.channelMutex.RLock()
+		onResubscribe := sc.onResubscribe
+		sc.channelMutex.RUnlock()
+		sc.stateMutex.Lock()
+		reconnected := sc.hasConnected
+		sc.hasConnected = true
+		sc.stateMutex.Unlock()
+		if reconnected && onResubscribe != nil {
+			onResubscribe(topics)
+		}
+		sc.heartbeat.connected()
+		sc.setState(StateConnected)
 
-	sc.manager.On("reconnect_attempt", func(...any) {
-		utils.Log().Warning("Manager Reconnect Attempt")
+		connectedOnce.Do(func() { close(connected) })
 	})
 
-	sc.manager.On("reconnect_error", func(errs ...any) {
-		utils.Log().Warning("Manager Reconnect Error: %v", errs)
+	sc.io.On("connect_error", func(args ...any) {
+		logger().Warnf("Connection error: %v", args)
+
+		// Attempt to reconnect after error
+		if !io.Connected() && ctx.Err() == nil {
+			if !sc.scheduleReconnect(ctx, io) {
+				gaveUpOnce.Do(func() { close(gaveUp) })
+			}
+		}
 	})
 
-	sc.manager.On("reconnect_failed", func(errs ...any) {
-		utils.Log().Warning("Manager Reconnect Failed: %v", errs)
+	sc.io.On("disconnect", func(args ...any) {
+		logger().Warnf("Disconnected from WebSocket server: %+v", args)
+		sc.setState(StateDisconnected)
+
+		// Disconnects requested by the client are not retried
+		if ctx.Err() == nil && !(len(args) > 0 && args[0] == "io client disconnect") {
+			sc.scheduleReconnect(ctx, io)
+		}
 	})
 
-	// Using default namespace
-	io := sc.manager.Socket("/", opts)
-	sc.io = io
+	go sc.monitorHealth(ctx)
+	go sc.monitorHeartbeat(ctx, io)
 
-	// Print detailed socket information for debugging
-	utils.Log().Info("Socket object initialized: %v", io)
-	utils.Log().Info("Socket ID: %v", io.Id())
-	utils.Log().Info("Socket connected: %v", io.Connected())
+	sc.channelMutex.RLock()
+	if sc.pool != nil {
+		sc.pool.start(ctx, sc)
+	}
+	sc.channelMutex.RUnlock()
+
+	io.Connect()
+
+	// Close the connection once the context is done
+	go func() {
+		<-ctx.Done()
+		logger().Infof("Shutting down...")
+		io.Disconnect()
+	}()
+
+	select {
+	case <-connected:
+		return nil
+	case <-gaveUp:
+		cancel()
+		return ErrReconnectGaveUp
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-	sc.io.On("connect", func(args ...any) {
-		utils.Log().Info("Connected to WebSocket server, ID: %v", io.Id())
-		utils.Log().Info("Connection state: %v", io.Connected())
+// Close unsubscribes from all topics, disconnects, stops the client's
+// background goroutines and closes every event, topic and Events channel so
+// that range loops over them terminate. The client cannot be reused after
+// Close; create a new one instead. Calling Close more than once is safe.
+func (sc *SocketClient) Close() {
+	sc.closeOnce.Do(func() {
+		sc.UnsubscribeAll()
 
-		// Subscribe to topics after connection is established
-		subscribeToTopics(sc)
-	})
+		sc.channelMutex.Lock()
+		defer sc.channelMutex.Unlock()
 
-	sc.io.On("connect_error", func(args ...any) {
-		utils.Log().Warning("Connection error: %v", args)
+		if sc.cancel != nil {
+			sc.cancel()
+		}
+		if sc.io != nil {
+			sc.io.Disconnect()
+		}
 
-		// Attempt to reconnect after error
-		if !io.Connected() {
-			utils.Log().Info("Attempting to reconnect...")
-			io.Connect()
+		sc.closed = true
+		for _, ch := range sc.eventChannels {
+			sc.closeChannel(ch)
+		}
+		for topic, ch := range sc.topicChannels {
+			delete(sc.topicChannels, topic)
+			sc.closeChannel(ch)
 		}
+		if sc.multiplexed != nil {
+			sc.closeChannel(sc.multiplexed)
+		}
+		logger().Infof("WebSocket client closed")
 	})
+}
 
-	sc.io.On("disconnect", func(args ...any) {
-		utils.Log().Warning("Disconnected from WebSocket server: %+v", args)
-	})
+// Init connects and blocks until SIGINT or SIGTERM is received.
+//
+// Deprecated: Init installs its own signal handler, which is unsuitable when
+// embedding the client in a service. Use Connect with a context instead.
+func (sc *SocketClient) Init() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := sc.Connect(ctx); err != nil {
+		logger().Warnf("WebSocket connection not established: %v", err)
+	}
+	<-ctx.Done()
+}
+
+// OnResubscribe registers a callback invoked after each reconnect with the
+// topics that were subscribed again
+func (sc *SocketClient) OnResubscribe(callback func(topics []string)) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
 
-	// Wait for termination signal
-	<-sigChan
-	utils.Log().Info("Shutting down...")
+	sc.onResubscribe = callback
+}
 
-	// Clean disconnect
-	if sc.io.Connected() {
-		sc.io.Disconnect()
+// Topics returns the topics the client is subscribed to
+func (sc *SocketClient) Topics() []string {
+	sc.channelMutex.RLock()
+	defer sc.channelMutex.RUnlock()
+
+	return append([]string{}, sc.topics...)
+}
+
+// Subscription describes a subscribed topic and the event it produces
+type Subscription struct {
+	Topic string
+	Event EventName
+	// Whether the topic has a dedicated channel from Subscribe
+	HasChannel bool
+	// Number of callbacks registered for the topic
+	Callbacks int
+	// Whether the topic is paused until its consumer catches up
+	Paused bool
+}
+
+// Subscriptions returns the current subscriptions in subscription order
+func (sc *SocketClient) Subscriptions() []Subscription {
+	topics := sc.Topics()
+
+	sc.channelMutex.RLock()
+	defer sc.channelMutex.RUnlock()
+
+	subscriptions := make([]Subscription, 0, len(topics))
+	for _, topic := range topics {
+		event, _ := TopicEvent(topic)
+		_, hasChannel := sc.topicChannels[topic]
+		subscriptions = append(subscriptions, Subscription{
+			Topic:      topic,
+			Event:      event,
+			HasChannel: hasChannel,
+			Callbacks:  len(sc.topicCallbacks[topic]),
+			Paused:     sc.isPaused(topic),
+		})
 	}
+	return subscriptions
 }
 
-// Helper function to subscribe to configured topics
-func subscribeToTopics(sc *SocketClient) {
-	if len(sc.topics) == 0 {
-		utils.Log().Info("No topics to subscribe to")
-		return
+// UnsubscribeAll removes every topic along with its callbacks and dedicated
+// channels. Per-event channels remain open.
+func (sc *SocketClient) UnsubscribeAll() {
+	sc.subscriptionMutex.Lock()
+	defer sc.subscriptionMutex.Unlock()
+
+	sc.channelMutex.Lock()
+	topics := sc.topics
+	sc.topics = []string{}
+	sc.channelMutex.Unlock()
+
+	// If already connected, unsubscribe from all topics at once
+	if len(topics) > 0 && sc.io != nil && sc.io.Connected() {
+		sc.io.Emit("unsubscribe", map[string][]string{
+			"params": topics,
+		})
+		logger().Infof("Unsubscribed from %d topics", len(topics))
 	}
 
-	utils.Log().Info("Subscribing to topics: %v", sc.topics)
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
 
-	// Subscribe to each topic by emitting the subscribe event
-	sc.io.Emit("subscribe", map[string][]string{
-		"params": sc.topics,
-	})
+	sc.topicCallbacks = make(map[string][]func(EventData))
+	sc.topicMiddleware = make(map[string][]EventMiddleware)
+	for topic, ch := range sc.topicChannels {
+		delete(sc.topicChannels, topic)
+		sc.closeChannel(ch)
+	}
+}
 
-	// Add an acknowledgment callback for the subscription
-	sc.io.EmitWithAck("subscribe", func(ack ...any) {
-		utils.Log().Info("Subscription acknowledgment: %v", ack)
-	}, map[string][]string{
-		"params": sc.topics,
-	})
+// Helper function to subscribe to configured topics. It returns the topics
+// included in the subscribe payload.
+func subscribeToTopics(sc *SocketClient) []string {
+	sc.subscriptionMutex.Lock()
+	defer sc.subscriptionMutex.Unlock()
+
+	// Paused topics are subscribed again once their consumers catch up
+	topics := slices.DeleteFunc(sc.Topics(), sc.isPaused)
+	if len(topics) == 0 {
+		logger().Infof("No topics to subscribe to")
+		return topics
+	}
 
-	// Setup event handlers with debug output
-	setupEventHandlers(sc)
+	logger().Infof("Subscribing to topics: %v", topics)
+
+	emitSubscribe(sc, topics, nil)
+
+	return topics
 }
 
 // Function to set up all event handlers
 func setupEventHandlers(sc *SocketClient) {
+	// The catch-all listener runs before the per-event handlers
+	sc.io.OnAny(sc.handleRawMessage)
+
+	// Errors reported by the server, e.g. for unknown topics
+	sc.io.On("error", sc.handleServerError)
+
 	// Setup a single handler for each event type
 	for _, event := range sc.events {
 		// Create a handler that can determine which topic triggered the event
@@ -234,27 +596,124 @@ func setupEventHandlers(sc *SocketClient) {
 	}
 }
 
-func createChannelEventHandler(sc *SocketClient, event types.EventName) func(...any) {
-	eventchannel, exists := sc.GetEventChannel(event)
-	if exists {
-		return func(data ...any) {
-			select {
-			case eventchannel <- EventData{
-				Event: event,
-				Data:  data,
-			}:
-				// Message sent successfully
-			default:
-				// Channel buffer is full, log a warning
-				utils.Log().Warning("Channel buffer full for event %s; dropping message", event)
+func createChannelEventHandler(sc *SocketClient, event EventName) func(...any) {
+	return func(data ...any) {
+		received := time.Now()
+		eventData := EventData{
+			Event:   event,
+			Data:    data,
+			Payload: decodeTypedPayload(event, data),
+		}
+		sc.metrics.record(event, received, time.Since(received), payloadEventTime(eventData.Payload))
+
+		sc.channelMutex.RLock()
+		eventData.Topic = resolveTopic(sc.topics, event, eventData.Payload, data)
+		middleware := sc.middlewareFor(event, eventData.Topic)
+		sc.channelMutex.RUnlock()
+
+		if eventData.Topic != "" {
+			sc.markReceived(eventData.Topic)
+		}
+
+		// Run middleware outside the lock so it may manage subscriptions
+		if middleware != nil {
+			var ok bool
+			if eventData, ok = applyMiddleware(middleware, eventData); !ok {
+				return
 			}
 		}
+
+		sc.channelMutex.RLock()
+		pool := sc.pool
+
+		if sc.closed {
+			sc.channelMutex.RUnlock()
+			return
+		}
+
+		// Deliver to the multiplexed stream before dispatch so it keeps arrival order
+		var blocked *blockedSend
+		if sc.multiplexed != nil {
+			blocked = sc.deliver(eventsKey, sc.multiplexed, sc.eventsConfig().Overflow, eventData)
+		}
+		sc.channelMutex.RUnlock()
+
+		// Wait for a full OverflowBlock channel without holding the lock
+		if blocked != nil {
+			blocked.send(sc)
+		}
+
+		if pool != nil && pool.submit(eventData) {
+			return
+		}
+		sc.dispatch(eventData)
 	}
-	return func(data ...any) {
-		utils.Log().Warning("Event channel not found for event: %s", event)
+}
+
+// dispatch delivers an event to its topic and event channels and invokes
+// the topic's callbacks
+func (sc *SocketClient) dispatch(eventData EventData) {
+	event := eventData.Event
+
+	sc.channelMutex.RLock()
+	if sc.closed {
+		sc.channelMutex.RUnlock()
+		return
+	}
+	callbacks := sc.topicCallbacks[eventData.Topic]
+
+	// Route to the dedicated topic channel if one was requested
+	var blocked []*blockedSend
+	topicChannel := sc.topicChannels[eventData.Topic]
+	if topicChannel != nil {
+		config := sc.topicChannelConfig(eventData.Topic)
+		if bs := sc.deliver(eventData.Topic, topicChannel, config.Overflow, eventData); bs != nil {
+			blocked = append(blocked, bs)
+		}
+		sc.checkBacklog(eventData.Topic, topicChannel, config)
+	}
+
+	if topicChannel == nil || sc.eventFanIn {
+		if eventchannel, exists := sc.eventChannels[event]; exists {
+			if bs := sc.deliver(string(event), eventchannel, sc.eventChannelConfig(event).Overflow, eventData); bs != nil {
+				blocked = append(blocked, bs)
+			}
+		} else {
+			logger().Warnf("Event channel not found for event: %s", event)
+		}
+	}
+	sc.channelMutex.RUnlock()
+
+	// Wait for full OverflowBlock channels without holding the lock, so a
+	// stalled consumer cannot block Subscribe, Unsubscribe or Close
+	sc.completeSends(blocked)
+
+	// Invoke callbacks outside the lock so they may manage subscriptions
+	for _, callback := range callbacks {
+		callback(eventData)
 	}
 }
 
-func setupEventHandler(io *socket.Socket, event types.EventName, function func(...any)) {
+func setupEventHandler(io streamTransport, event EventName, function func(...any)) {
 	io.On(event, function)
 }
+
+// decodeEventPayload converts the first argument of a socket.io event into v.
+// Event arguments arrive as generic decoded JSON, so they are re-marshaled
+// and parsed into the requested structure.
+func decodeEventPayload(data []any, v any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("event has no payload")
+	}
+
+	raw, err := json.Marshal(data[0])
+	if err != nil {
+		return fmt.Errorf("error marshaling event payload: %v", err)
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("error parsing event payload: %v", err)
+	}
+
+	return nil
+}
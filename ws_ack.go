@@ -0,0 +1,214 @@
+package pi42
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// SubscriptionResult is the server's answer to subscribing a topic
+type SubscriptionResult struct {
+	Topic string
+	// Whether the server acknowledged the subscribe message. False when the
+	// client was not connected; the topic is then subscribed on connect.
+	Acknowledged bool
+	// Non-nil if the topic is unsupported or was rejected by the server
+	Err error
+}
+
+// OnSubscriptionError registers a callback invoked when the server rejects a
+// topic or does not acknowledge a subscribe message. Rejected topics are
+// removed from the subscription list so they are not retried on reconnect.
+func (sc *SocketClient) OnSubscriptionError(callback func(topic string, err error)) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.subscriptionErrors = append(sc.subscriptionErrors, callback)
+}
+
+// SubscribeTopics subscribes to topics and waits for the server to
+// acknowledge them, returning a result per topic. Unsupported topics fail
+// without being sent. If ctx ends before all acknowledgements arrive, the
+// results received so far are returned with the context error.
+func (sc *SocketClient) SubscribeTopics(ctx context.Context, topics ...string) ([]SubscriptionResult, error) {
+	results := make([]SubscriptionResult, len(topics))
+	index := make(map[string]int, len(topics))
+
+	var valid []string
+	for i, topic := range topics {
+		results[i].Topic = topic
+		if _, ok := TopicEvent(topic); !ok {
+			results[i].Err = fmt.Errorf("unsupported topic: %s", topic)
+			continue
+		}
+		if _, dup := index[topic]; !dup {
+			index[topic] = i
+			valid = append(valid, topic)
+		}
+	}
+
+	sc.subscriptionMutex.Lock()
+	sc.channelMutex.Lock()
+	for _, topic := range valid {
+		if !slices.Contains(sc.topics, topic) {
+			sc.topics = append(sc.topics, topic)
+		}
+	}
+	sc.channelMutex.Unlock()
+
+	if len(valid) == 0 || sc.io == nil || !sc.io.Connected() {
+		sc.subscriptionMutex.Unlock()
+		return results, nil
+	}
+
+	acks := make(chan []SubscriptionResult, (len(valid)+subscribeBatchSize-1)/subscribeBatchSize)
+	batches := emitSubscribe(sc, valid, func(batch []SubscriptionResult) {
+		acks <- batch
+	})
+	sc.subscriptionMutex.Unlock()
+
+	for ; batches > 0; batches-- {
+		select {
+		case batch := <-acks:
+			for _, result := range batch {
+				results[index[result.Topic]] = result
+			}
+		case <-ctx.Done():
+			return results, ctx.Err()
+		}
+	}
+
+	// Results for duplicate topics mirror the first occurrence
+	for i, topic := range topics {
+		if j, ok := index[topic]; ok && j != i {
+			results[i] = results[j]
+		}
+	}
+	return results, nil
+}
+
+// handleSubscribeAck interprets the acknowledgement of a subscribe batch,
+// drops rejected topics and notifies the error callbacks
+func (sc *SocketClient) handleSubscribeAck(topics []string, ack []any, err error) []SubscriptionResult {
+	results := make([]SubscriptionResult, len(topics))
+	for i, topic := range topics {
+		results[i] = SubscriptionResult{Topic: topic, Acknowledged: err == nil, Err: err}
+	}
+	if err != nil {
+		err = fmt.Errorf("subscribe not acknowledged: %v", err)
+		for i := range results {
+			results[i].Err = err
+		}
+	} else {
+		rejected, rejectErr := parseSubscribeAck(ack)
+		if rejectErr != nil {
+			for i := range results {
+				if len(rejected) == 0 || slices.Contains(rejected, results[i].Topic) {
+					results[i].Err = fmt.Errorf("subscription rejected: %v", rejectErr)
+				}
+			}
+		}
+	}
+
+	var failed []SubscriptionResult
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		logger().Infof("Subscription acknowledgment: %v", ack)
+		return results
+	}
+
+	sc.channelMutex.Lock()
+	for _, result := range failed {
+		// Unacknowledged topics may still be valid; only rejections are dropped
+		if result.Acknowledged {
+			sc.topics = slices.DeleteFunc(sc.topics, func(t string) bool { return t == result.Topic })
+		}
+	}
+	callbacks := append([]func(string, error){}, sc.subscriptionErrors...)
+	sc.channelMutex.Unlock()
+
+	for _, result := range failed {
+		logger().Warnf("Subscription to %s failed: %v", result.Topic, result.Err)
+		for _, callback := range callbacks {
+			callback(result.Topic, result.Err)
+		}
+	}
+	return results
+}
+
+// handleServerError reports "error" events sent by the server. Errors that
+// name topics are treated as subscription rejections.
+func (sc *SocketClient) handleServerError(args ...any) {
+	topics, err := parseSubscribeAck(args)
+	if err == nil {
+		logger().Warnf("Server error: %v", args)
+		return
+	}
+	if len(topics) == 0 {
+		logger().Warnf("Server error: %v", err)
+		return
+	}
+
+	sc.handleSubscribeAck(topics, []any{map[string]any{"error": err.Error(), "params": anySlice(topics)}}, nil)
+}
+
+// anySlice converts topics to the form decoded from JSON
+func anySlice(topics []string) []any {
+	values := make([]any, len(topics))
+	for i, topic := range topics {
+		values[i] = topic
+	}
+	return values
+}
+
+// parseSubscribeAck looks for an error in a subscribe acknowledgement. It
+// returns the error and the topics it applies to, or no topics if it
+// applies to the whole batch. Acknowledgements are objects carrying an
+// error, message, success or status field.
+func parseSubscribeAck(ack []any) ([]string, error) {
+	for _, arg := range ack {
+		m, ok := arg.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		failed := false
+		if e, ok := m["error"]; ok && e != nil && e != false && e != "" {
+			failed = true
+		}
+		if success, ok := m["success"].(bool); ok && !success {
+			failed = true
+		}
+		if status, ok := m["status"].(string); ok && (status == "error" || status == "failed") {
+			failed = true
+		}
+		if !failed {
+			continue
+		}
+
+		message := fmt.Sprint(m["error"])
+		if msg, ok := m["message"].(string); ok && msg != "" {
+			message = msg
+		}
+
+		var topics []string
+		for _, key := range []string{"topic", "params"} {
+			switch v := m[key].(type) {
+			case string:
+				topics = append(topics, v)
+			case []any:
+				for _, t := range v {
+					if s, ok := t.(string); ok {
+						topics = append(topics, s)
+					}
+				}
+			}
+		}
+		return topics, fmt.Errorf("%s", message)
+	}
+	return nil, nil
+}
@@ -0,0 +1,219 @@
+package pi42
+
+import (
+	"sync"
+)
+
+// defaultChannelBufferSize is the buffer size of event and topic channels
+const defaultChannelBufferSize = 100
+
+// OverflowPolicy controls what happens when a channel's buffer is full
+type OverflowPolicy int
+
+// Supported overflow policies
+const (
+	// OverflowDropNewest discards the incoming message
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered message to make room
+	OverflowDropOldest
+	// OverflowBlock waits until the consumer makes room. The wait happens
+	// on the goroutine reading the connection (or the dispatching worker),
+	// so a slow consumer stalls delivery of all other events. Subscribe,
+	// Unsubscribe and Close are not blocked: closing or replacing the
+	// channel abandons the waiting message, which is counted as dropped.
+	OverflowBlock
+)
+
+// ChannelConfig configures buffering for an event or topic channel
+type ChannelConfig struct {
+	BufferSize int
+	Overflow   OverflowPolicy
+	// Topic channels only: when the backlog reaches PauseAt messages, the
+	// topic is unsubscribed upstream until the consumer drains it to
+	// ResumeAt messages, then subscribed again. Events published while
+	// paused are missed. 0 disables pausing.
+	PauseAt  int
+	ResumeAt int
+}
+
+// defaultChannelConfig returns the configuration used when none was set
+func defaultChannelConfig() ChannelConfig {
+	return ChannelConfig{
+		BufferSize: defaultChannelBufferSize,
+		Overflow:   OverflowDropNewest,
+	}
+}
+
+// SetEventChannelConfig sets buffering for an event channel. The channel is
+// replaced, so this must be called before GetEventChannel.
+func (sc *SocketClient) SetEventChannelConfig(event EventName, config ChannelConfig) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.eventConfigs[event] = config
+	if old, exists := sc.eventChannels[event]; exists {
+		sc.abortSends(old)
+	}
+	sc.eventChannels[event] = make(chan EventData, config.BufferSize)
+}
+
+// SetTopicChannelConfig sets buffering for a topic channel. It applies to
+// channels created by subsequent Subscribe calls for the topic.
+func (sc *SocketClient) SetTopicChannelConfig(topic string, config ChannelConfig) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.topicConfigs[topic] = config
+}
+
+// DroppedMessages returns the number of messages dropped because of full
+// buffers, keyed by event name or topic
+func (sc *SocketClient) DroppedMessages() map[string]uint64 {
+	sc.dropMutex.Lock()
+	defer sc.dropMutex.Unlock()
+
+	snapshot := make(map[string]uint64, len(sc.drops))
+	for key, count := range sc.drops {
+		snapshot[key] = count
+	}
+	return snapshot
+}
+
+// eventChannelConfig returns the configuration for an event channel.
+// The caller must hold channelMutex.
+func (sc *SocketClient) eventChannelConfig(event EventName) ChannelConfig {
+	if config, ok := sc.eventConfigs[event]; ok {
+		return config
+	}
+	return defaultChannelConfig()
+}
+
+// topicChannelConfig returns the configuration for a topic channel.
+// The caller must hold channelMutex.
+func (sc *SocketClient) topicChannelConfig(topic string) ChannelConfig {
+	if config, ok := sc.topicConfigs[topic]; ok {
+		return config
+	}
+	return defaultChannelConfig()
+}
+
+// pendingSends tracks blocking sends to a channel that wait outside
+// channelMutex, so the channel is only closed once they have given up
+type pendingSends struct {
+	abort chan struct{}
+	wg    sync.WaitGroup
+}
+
+// blockedSend is an OverflowBlock send that found the channel full. It is
+// completed with send after channelMutex is released.
+type blockedSend struct {
+	key     string
+	ch      chan EventData
+	data    EventData
+	pending *pendingSends
+}
+
+// send waits until the consumer takes the message or the channel is closed
+func (bs *blockedSend) send(sc *SocketClient) {
+	defer bs.pending.wg.Done()
+
+	select {
+	case bs.ch <- bs.data:
+	case <-bs.pending.abort:
+		sc.recordDrop(bs.key)
+	}
+}
+
+// completeSends performs blocked sends in order. The caller must not hold
+// channelMutex.
+func (sc *SocketClient) completeSends(sends []*blockedSend) {
+	for _, bs := range sends {
+		bs.send(sc)
+	}
+}
+
+// abortSends releases blocked sends waiting on ch. The caller must hold
+// channelMutex for writing, so no new sends to ch can start.
+func (sc *SocketClient) abortSends(ch chan EventData) {
+	sc.pendingMutex.Lock()
+	pending := sc.pendingSends[ch]
+	delete(sc.pendingSends, ch)
+	sc.pendingMutex.Unlock()
+
+	if pending != nil {
+		close(pending.abort)
+		pending.wg.Wait()
+	}
+}
+
+// closeChannel closes ch once blocked sends to it have been released.
+// The caller must hold channelMutex for writing.
+func (sc *SocketClient) closeChannel(ch chan EventData) {
+	sc.abortSends(ch)
+	close(ch)
+}
+
+// deliver sends data on ch according to the overflow policy, counting drops
+// under key. The caller must hold channelMutex. With OverflowBlock and a
+// full channel, the send is returned so the caller can complete it after
+// releasing the lock.
+func (sc *SocketClient) deliver(key string, ch chan EventData, policy OverflowPolicy, data EventData) *blockedSend {
+	switch policy {
+	case OverflowBlock:
+		select {
+		case ch <- data:
+			return nil
+		default:
+		}
+
+		sc.pendingMutex.Lock()
+		if sc.pendingSends == nil {
+			sc.pendingSends = make(map[chan EventData]*pendingSends)
+		}
+		pending := sc.pendingSends[ch]
+		if pending == nil {
+			pending = &pendingSends{abort: make(chan struct{})}
+			sc.pendingSends[ch] = pending
+		}
+		pending.wg.Add(1)
+		sc.pendingMutex.Unlock()
+
+		return &blockedSend{key: key, ch: ch, data: data, pending: pending}
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case ch <- data:
+				return nil
+			default:
+			}
+			// Make room by discarding the oldest buffered message
+			select {
+			case <-ch:
+				sc.recordDrop(key)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case ch <- data:
+		default:
+			sc.recordDrop(key)
+		}
+		return nil
+	}
+}
+
+// recordDrop increments the drop counter for key
+func (sc *SocketClient) recordDrop(key string) {
+	sc.dropMutex.Lock()
+	sc.drops[key]++
+	count := sc.drops[key]
+	sc.dropMutex.Unlock()
+
+	// Log the first drop and then periodically to avoid flooding the log
+	if count == 1 || count%1000 == 0 {
+		logger().Warnf("Channel buffer full for %s; %d messages dropped", key, count)
+	}
+}
@@ -0,0 +1,131 @@
+package pi42
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// subscribeBatchSize is the maximum number of topics sent in one subscribe message
+const subscribeBatchSize = 50
+
+// SubscribeAllSymbols subscribes to the given channels for every contract in
+// exchangeInfo, typically client.Contracts(). Channels use the topic suffix
+// format, e.g. "markPrice", "ticker", "aggTrade", "depth_0.1" or "kline_1m".
+// A bare "depth" uses each symbol's configured grouping. It returns the
+// topics that were added; subscribe messages are sent in batches.
+func (sc *SocketClient) SubscribeAllSymbols(exchangeInfo map[string]ContractInfo, channels ...string) ([]string, error) {
+	if len(exchangeInfo) == 0 {
+		return nil, fmt.Errorf("exchange info is empty")
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("at least one channel is required")
+	}
+	for _, channel := range channels {
+		if _, ok := TopicEvent("@" + channel); !ok {
+			return nil, fmt.Errorf("unsupported channel: %s", channel)
+		}
+	}
+
+	symbols := make([]string, 0, len(exchangeInfo))
+	for symbol := range exchangeInfo {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var candidates []string
+	for _, symbol := range symbols {
+		for _, channel := range channels {
+			if channel == "depth" {
+				candidates = append(candidates, DepthTopic(symbol, sc.depthGrouping(symbol)))
+			} else {
+				candidates = append(candidates, strings.ToLower(symbol)+"@"+channel)
+			}
+		}
+	}
+
+	sc.subscriptionMutex.Lock()
+	defer sc.subscriptionMutex.Unlock()
+
+	sc.channelMutex.Lock()
+	existing := make(map[string]bool, len(sc.topics))
+	for _, topic := range sc.topics {
+		existing[topic] = true
+	}
+
+	var added []string
+	for _, topic := range candidates {
+		if existing[topic] {
+			continue
+		}
+		existing[topic] = true
+		added = append(added, topic)
+	}
+
+	sc.topics = append(sc.topics, added...)
+	sc.channelMutex.Unlock()
+
+	// If already connected, subscribe to the new topics immediately
+	if sc.io != nil && sc.io.Connected() {
+		emitSubscribe(sc, added, nil)
+	}
+
+	logger().Infof("Added %d topics for %d symbols", len(added), len(symbols))
+	return added, nil
+}
+
+// AddSymbolStreams subscribes to streams for a symbol, building the topics
+// so they need not be formatted by hand:
+//
+//	sc.AddSymbolStreams("BTCINR", pi42.StreamDepth, pi42.StreamKline1m, pi42.StreamTicker)
+//
+// It returns the topics of the streams.
+func (sc *SocketClient) AddSymbolStreams(symbol string, streams ...SymbolStream) ([]string, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+
+	topics := make([]string, 0, len(streams))
+	for _, stream := range streams {
+		topic := sc.symbolTopic(symbol, stream)
+		if _, ok := TopicEvent(topic); !ok {
+			return nil, fmt.Errorf("unsupported stream: %s", stream)
+		}
+		if stream != StreamDepth && strings.HasPrefix(string(stream), "kline_") {
+			if err := KlineInterval(strings.TrimPrefix(string(stream), "kline_")).Validate(); err != nil {
+				return nil, err
+			}
+		}
+		topics = append(topics, topic)
+	}
+
+	for _, topic := range topics {
+		event, _ := TopicEvent(topic)
+		sc.AddStream(topic, event)
+	}
+	return topics, nil
+}
+
+// emitSubscribe sends subscribe messages for topics in batches of
+// subscribeBatchSize, keeping each payload within server limits. done, if
+// not nil, is invoked with the results of each batch once acknowledged. It
+// returns the number of batches sent.
+func emitSubscribe(sc *SocketClient, topics []string, done func([]SubscriptionResult)) int {
+	batches := 0
+	for start := 0; start < len(topics); start += subscribeBatchSize {
+		end := min(start+subscribeBatchSize, len(topics))
+		batch := topics[start:end]
+
+		// Subscribe with an acknowledgment callback for the subscription
+		sc.io.EmitWithAck("subscribe", func(ack []any, err error) {
+			results := sc.handleSubscribeAck(batch, ack, err)
+			if done != nil {
+				done(results)
+			}
+		}, map[string][]string{
+			"params": batch,
+		})
+		batches++
+	}
+	return batches
+}
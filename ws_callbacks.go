@@ -0,0 +1,85 @@
+package pi42
+
+// onTopic registers a callback for a topic and subscribes to it
+func (sc *SocketClient) onTopic(topic string, event EventName, callback func(EventData)) {
+	sc.channelMutex.Lock()
+	sc.topicCallbacks[topic] = append(sc.topicCallbacks[topic], callback)
+	sc.channelMutex.Unlock()
+
+	sc.AddStream(topic, event)
+}
+
+// SetDepthGrouping sets the price grouping used by OnDepth for a symbol
+func (sc *SocketClient) SetDepthGrouping(symbol, grouping string) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.depthGroupings[NormalizeSymbol(symbol)] = grouping
+}
+
+// depthGrouping returns the configured depth grouping for a symbol. Without
+// one, the default grouping is used if the contract supports it, and the
+// finest supported grouping otherwise.
+func (sc *SocketClient) depthGrouping(symbol string) string {
+	sc.channelMutex.RLock()
+	grouping, ok := sc.depthGroupings[NormalizeSymbol(symbol)]
+	market := sc.market
+	sc.channelMutex.RUnlock()
+
+	if ok {
+		return grouping
+	}
+	if market != nil {
+		if contract, err := market.client.GetContractInfo(symbol); err == nil {
+			return defaultGroupingFor(contract)
+		}
+	}
+	return defaultDepthGrouping
+}
+
+// OnDepth subscribes to a symbol's order book updates and invokes callback
+// for each one. The price grouping is set with SetDepthGrouping.
+func (sc *SocketClient) OnDepth(symbol string, callback func(DepthUpdateEvent)) {
+	topic := DepthTopic(symbol, sc.depthGrouping(symbol))
+	sc.onTopic(topic, "depthUpdate", func(e EventData) {
+		if depth, ok := e.Payload.(*DepthUpdateEvent); ok {
+			callback(*depth)
+		}
+	})
+}
+
+// OnMarkPrice subscribes to a symbol's mark price updates
+func (sc *SocketClient) OnMarkPrice(symbol string, callback func(MarkPriceEvent)) {
+	sc.onTopic(MarkPriceTopic(symbol), "markPriceUpdate", func(e EventData) {
+		if markPrice, ok := e.Payload.(*MarkPriceEvent); ok {
+			callback(*markPrice)
+		}
+	})
+}
+
+// OnKline subscribes to a symbol's candles for an interval
+func (sc *SocketClient) OnKline(symbol string, interval KlineInterval, callback func(KlineEvent)) {
+	sc.onTopic(KlineTopic(symbol, interval), "kline", func(e EventData) {
+		if kline, ok := e.Payload.(*KlineEvent); ok {
+			callback(*kline)
+		}
+	})
+}
+
+// OnTicker subscribes to a symbol's 24hr ticker updates
+func (sc *SocketClient) OnTicker(symbol string, callback func(TickerEvent)) {
+	sc.onTopic(TickerTopic(symbol), "24hrTicker", func(e EventData) {
+		if ticker, ok := e.Payload.(*TickerEvent); ok {
+			callback(*ticker)
+		}
+	})
+}
+
+// OnAggTrade subscribes to a symbol's aggregated trades
+func (sc *SocketClient) OnAggTrade(symbol string, callback func(AggTradeEvent)) {
+	sc.onTopic(AggTradeTopic(symbol), "aggTrade", func(e EventData) {
+		if trade, ok := e.Payload.(*AggTradeEvent); ok {
+			callback(*trade)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package pi42
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// WorkerPoolConfig configures worker-pool dispatch of WebSocket events
+type WorkerPoolConfig struct {
+	// Number of workers per event type when not set in Concurrency, defaults to 1
+	DefaultConcurrency int
+	// Number of workers for specific event types. With more than one worker,
+	// events of that type may be delivered out of order.
+	Concurrency map[EventName]int
+	// Capacity of each event type's queue, defaults to defaultChannelBufferSize
+	QueueSize int
+	// What happens when an event type's queue is full when not set in
+	// Overflow, defaults to OverflowDropNewest. OverflowBlock makes the
+	// socket reader wait for a free slot; the reader is shared by all event
+	// types, so a full blocking queue delays every event type.
+	DefaultOverflow OverflowPolicy
+	// Overflow policies for specific event types
+	Overflow map[EventName]OverflowPolicy
+}
+
+// DispatchStats reports the state of an event type's dispatch queue
+type DispatchStats struct {
+	Queued    int    // Number of events waiting in the queue
+	Capacity  int    // Queue capacity
+	Workers   int    // Number of workers for the event type
+	Processed uint64 // Number of events delivered by the workers
+	Blocked   uint64 // Number of times the reader waited on a full queue
+	Dropped   uint64 // Number of events discarded because the queue was full or the connection ended
+}
+
+// eventQueue is the dispatch queue and counters of one event type
+type eventQueue struct {
+	ch        chan EventData
+	workers   int
+	overflow  OverflowPolicy
+	processed atomic.Uint64
+	blocked   atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// workerPool dispatches events through a bounded queue per event type
+type workerPool struct {
+	queues map[EventName]*eventQueue
+	// Closed when the context of the current connection is done; replaced
+	// by every start
+	done   chan struct{}
+	doneMu sync.Mutex
+}
+
+// EnableWorkerPool dispatches events through a bounded worker pool instead
+// of delivering them on the socket's read goroutine, so a slow channel
+// consumer or callback for one event type doesn't delay the others. Must be
+// called before Connect.
+func (sc *SocketClient) EnableWorkerPool(config WorkerPoolConfig) {
+	if config.DefaultConcurrency <= 0 {
+		config.DefaultConcurrency = 1
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultChannelBufferSize
+	}
+
+	pool := &workerPool{
+		queues: make(map[EventName]*eventQueue, len(sc.events)),
+		done:   make(chan struct{}),
+	}
+	for _, event := range sc.events {
+		workers := config.DefaultConcurrency
+		if n, ok := config.Concurrency[event]; ok && n > 0 {
+			workers = n
+		}
+		overflow := config.DefaultOverflow
+		if policy, ok := config.Overflow[event]; ok {
+			overflow = policy
+		}
+		pool.queues[event] = &eventQueue{
+			ch:       make(chan EventData, config.QueueSize),
+			workers:  workers,
+			overflow: overflow,
+		}
+	}
+
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.pool = pool
+}
+
+// DispatchStats returns queue metrics per event type. It returns nil when
+// the worker pool is not enabled.
+func (sc *SocketClient) DispatchStats() map[EventName]DispatchStats {
+	sc.channelMutex.RLock()
+	pool := sc.pool
+	sc.channelMutex.RUnlock()
+
+	if pool == nil {
+		return nil
+	}
+
+	stats := make(map[EventName]DispatchStats, len(pool.queues))
+	for event, queue := range pool.queues {
+		stats[event] = DispatchStats{
+			Queued:    len(queue.ch),
+			Capacity:  cap(queue.ch),
+			Workers:   queue.workers,
+			Processed: queue.processed.Load(),
+			Blocked:   queue.blocked.Load(),
+			Dropped:   queue.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// start launches the workers, which run until ctx is done
+func (p *workerPool) start(ctx context.Context, sc *SocketClient) {
+	// Each connection gets its own done channel, so a pool restarted after
+	// a reconnect or playback doesn't see the previous context as done
+	done := make(chan struct{})
+	p.doneMu.Lock()
+	p.done = done
+	p.doneMu.Unlock()
+
+	for _, queue := range p.queues {
+		for i := 0; i < queue.workers; i++ {
+			go func(queue *eventQueue) {
+				for {
+					select {
+					case eventData := <-queue.ch:
+						sc.dispatch(eventData)
+						queue.processed.Add(1)
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(queue)
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		close(done)
+	}()
+}
+
+// submit queues an event, applying the queue's overflow policy when it is
+// full. It returns false if the event type has no queue.
+func (p *workerPool) submit(eventData EventData) bool {
+	queue, ok := p.queues[eventData.Event]
+	if !ok {
+		return false
+	}
+
+	select {
+	case queue.ch <- eventData:
+		return true
+	default:
+	}
+
+	switch queue.overflow {
+	case OverflowBlock:
+		// Wait below for a worker to free a slot
+	case OverflowDropOldest:
+		for {
+			// Make room by discarding the oldest queued event
+			select {
+			case <-queue.ch:
+				queue.dropped.Add(1)
+			default:
+			}
+			select {
+			case queue.ch <- eventData:
+				return true
+			default:
+			}
+		}
+	default:
+		queue.dropped.Add(1)
+		return true
+	}
+
+	p.doneMu.Lock()
+	done := p.done
+	p.doneMu.Unlock()
+
+	queue.blocked.Add(1)
+	select {
+	case queue.ch <- eventData:
+	case <-done:
+		queue.dropped.Add(1)
+	}
+	return true
+}
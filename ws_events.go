@@ -0,0 +1,36 @@
+package pi42
+
+// eventsKey identifies the multiplexed stream in drop counters
+const eventsKey = "events"
+
+// Events returns a single channel that receives every event in arrival
+// order across all topics and event types, for consumers that prefer one
+// loop over a goroutine per channel. Events are delivered here in addition
+// to the per-event and per-topic channels. Repeated calls return the same channel.
+func (sc *SocketClient) Events() <-chan EventData {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	if sc.multiplexed == nil {
+		sc.multiplexed = make(chan EventData, sc.eventsConfig().BufferSize)
+	}
+	return sc.multiplexed
+}
+
+// SetEventsConfig sets buffering for the channel returned by Events. It must
+// be called before Events.
+func (sc *SocketClient) SetEventsConfig(config ChannelConfig) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.multiplexedConfig = &config
+}
+
+// eventsConfig returns the configuration of the multiplexed channel.
+// The caller must hold channelMutex.
+func (sc *SocketClient) eventsConfig() ChannelConfig {
+	if sc.multiplexedConfig != nil {
+		return *sc.multiplexedConfig
+	}
+	return defaultChannelConfig()
+}
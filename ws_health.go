@@ -0,0 +1,123 @@
+package pi42
+
+import (
+	"context"
+	"time"
+)
+
+// TopicHealth describes the data flow of a subscribed topic
+type TopicHealth struct {
+	Topic        string
+	LastReceived time.Time // Zero if no message has been received
+	Messages     uint64    // Number of messages received
+	Stale        bool      // Whether no data arrived within the stale timeout
+}
+
+// topicStats tracks message arrival for a topic
+type topicStats struct {
+	lastReceived time.Time
+	messages     uint64
+	stale        bool
+}
+
+// SetStaleTimeout enables staleness detection. When a subscribed topic
+// receives no data for longer than timeout, callback is invoked once with
+// the topic and the time of its last message; it is invoked again only
+// after the topic has recovered. Must be called before Connect.
+func (sc *SocketClient) SetStaleTimeout(timeout time.Duration, callback func(topic string, lastReceived time.Time)) {
+	sc.healthMutex.Lock()
+	defer sc.healthMutex.Unlock()
+
+	sc.staleTimeout = timeout
+	sc.onStale = callback
+}
+
+// Health returns the data flow status of every subscribed topic
+func (sc *SocketClient) Health() []TopicHealth {
+	topics := sc.Topics()
+
+	sc.healthMutex.Lock()
+	defer sc.healthMutex.Unlock()
+
+	health := make([]TopicHealth, 0, len(topics))
+	for _, topic := range topics {
+		h := TopicHealth{Topic: topic}
+		if stats, ok := sc.topicStats[topic]; ok {
+			h.LastReceived = stats.lastReceived
+			h.Messages = stats.messages
+			h.Stale = stats.stale
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// markReceived records the arrival of a message for a topic
+func (sc *SocketClient) markReceived(topic string) {
+	sc.healthMutex.Lock()
+	defer sc.healthMutex.Unlock()
+
+	stats, ok := sc.topicStats[topic]
+	if !ok {
+		stats = &topicStats{}
+		sc.topicStats[topic] = stats
+	}
+	stats.lastReceived = time.Now()
+	stats.messages++
+	stats.stale = false
+}
+
+// monitorHealth checks subscribed topics for staleness until ctx is done
+func (sc *SocketClient) monitorHealth(ctx context.Context) {
+	sc.healthMutex.Lock()
+	timeout := sc.staleTimeout
+	sc.healthMutex.Unlock()
+	if timeout <= 0 {
+		return
+	}
+
+	// Topics that never received data are measured from the start of monitoring
+	started := time.Now()
+	// Tiny timeouts would truncate to a zero period, which NewTicker rejects
+	ticker := time.NewTicker(max(timeout/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			type staleTopic struct {
+				topic        string
+				lastReceived time.Time
+			}
+			var stale []staleTopic
+
+			topics := sc.Topics()
+			sc.healthMutex.Lock()
+			for _, topic := range topics {
+				stats, ok := sc.topicStats[topic]
+				if !ok {
+					stats = &topicStats{}
+					sc.topicStats[topic] = stats
+				}
+				last := stats.lastReceived
+				if last.IsZero() {
+					last = started
+				}
+				if !stats.stale && now.Sub(last) > timeout {
+					stats.stale = true
+					stale = append(stale, staleTopic{topic, stats.lastReceived})
+				}
+			}
+			callback := sc.onStale
+			sc.healthMutex.Unlock()
+
+			if callback != nil {
+				for _, s := range stale {
+					callback(s.topic, s.lastReceived)
+				}
+			}
+		}
+	}
+}
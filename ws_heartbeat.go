@@ -0,0 +1,139 @@
+package pi42
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HeartbeatStats summarizes the engine.io heartbeats received from the
+// server. Engine.io v4 pings are sent by the server and answered without an
+// acknowledgement, so the round trip cannot be observed by the client; the
+// delay between pings is reported instead, which grows when the network or
+// the server stalls.
+type HeartbeatStats struct {
+	Pings       uint64        // Number of pings received
+	LastPing    time.Time     // Time of the latest ping; zero if none was received
+	AvgInterval time.Duration // Average time between consecutive pings
+	MaxInterval time.Duration // Longest time between consecutive pings
+	Missed      uint64        // Number of times no ping arrived within the heartbeat timeout
+	Reconnects  uint64        // Number of reconnects forced by missed heartbeats
+}
+
+// heartbeatMonitor records server pings and detects missed heartbeats
+type heartbeatMonitor struct {
+	timeout time.Duration
+
+	pings         uint64
+	lastPing      time.Time
+	since         time.Time // Start of the current connection
+	intervalTotal time.Duration
+	intervalMax   time.Duration
+	intervals     uint64
+	missed        uint64
+	reconnects    uint64
+	mu            sync.Mutex
+}
+
+// SetHeartbeatTimeout enables missed heartbeat detection. When no ping
+// arrives from the server within timeout, the connection is closed and
+// re-established through the reconnect policy instead of waiting for a
+// TCP-level failure. It should exceed the server's ping interval (25s by
+// default in socket.io). Must be called before Connect.
+func (sc *SocketClient) SetHeartbeatTimeout(timeout time.Duration) {
+	sc.heartbeat.mu.Lock()
+	defer sc.heartbeat.mu.Unlock()
+
+	sc.heartbeat.timeout = timeout
+}
+
+// HeartbeatStats returns a snapshot of the heartbeat statistics
+func (sc *SocketClient) HeartbeatStats() HeartbeatStats {
+	h := &sc.heartbeat
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := HeartbeatStats{
+		Pings:       h.pings,
+		LastPing:    h.lastPing,
+		MaxInterval: h.intervalMax,
+		Missed:      h.missed,
+		Reconnects:  h.reconnects,
+	}
+	if h.intervals > 0 {
+		stats.AvgInterval = h.intervalTotal / time.Duration(h.intervals)
+	}
+	return stats
+}
+
+// connected starts measuring heartbeats for a new connection
+func (h *heartbeatMonitor) connected() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.since = time.Now()
+	h.lastPing = time.Time{}
+}
+
+// ping records a ping received from the server
+func (h *heartbeatMonitor) ping() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !h.lastPing.IsZero() {
+		interval := now.Sub(h.lastPing)
+		h.intervalTotal += interval
+		h.intervalMax = max(h.intervalMax, interval)
+		h.intervals++
+	}
+	h.pings++
+	h.lastPing = now
+}
+
+// monitorHeartbeat closes the connection when heartbeats are missed, so the
+// reconnect policy re-establishes it, until ctx is done
+func (sc *SocketClient) monitorHeartbeat(ctx context.Context, io streamTransport) {
+	h := &sc.heartbeat
+	h.mu.Lock()
+	timeout := h.timeout
+	h.mu.Unlock()
+	if timeout <= 0 {
+		return
+	}
+
+	// Tiny timeouts would truncate to a zero period, which NewTicker rejects
+	ticker := time.NewTicker(max(timeout/4, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !io.Connected() {
+				continue
+			}
+
+			h.mu.Lock()
+			last := h.lastPing
+			if last.IsZero() {
+				last = h.since
+			}
+			missed := !last.IsZero() && now.Sub(last) > timeout
+			if missed {
+				h.missed++
+				h.reconnects++
+				// Measure the next connection from scratch
+				h.since = time.Time{}
+				h.lastPing = time.Time{}
+			}
+			h.mu.Unlock()
+
+			if missed {
+				logger().Warnf("No heartbeat for %v; forcing reconnect", now.Sub(last))
+				io.Drop()
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package pi42
+
+import (
+	"strings"
+)
+
+// EventMiddleware inspects an event before it is delivered to channels and
+// callbacks. It returns the event to deliver, possibly modified or enriched,
+// and false to drop the event.
+type EventMiddleware func(EventData) (EventData, bool)
+
+// Use registers middleware for every event of a type, e.g. "kline".
+// Middleware runs in registration order on the socket's read goroutine,
+// before topic middleware and before the event reaches the Events stream,
+// channels or callbacks.
+func (sc *SocketClient) Use(event EventName, middleware EventMiddleware) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.eventMiddleware[event] = append(sc.eventMiddleware[event], middleware)
+}
+
+// UseTopic registers middleware for a single subscription. It is removed
+// when the topic is unsubscribed.
+func (sc *SocketClient) UseTopic(topic string, middleware EventMiddleware) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.topicMiddleware[topic] = append(sc.topicMiddleware[topic], middleware)
+}
+
+// middlewareFor returns the middleware chain for an event and topic. The
+// caller must hold channelMutex.
+func (sc *SocketClient) middlewareFor(event EventName, topic string) []EventMiddleware {
+	eventChain := sc.eventMiddleware[event]
+	topicChain := sc.topicMiddleware[topic]
+	if len(eventChain) == 0 && len(topicChain) == 0 {
+		return nil
+	}
+	return append(append([]EventMiddleware{}, eventChain...), topicChain...)
+}
+
+// applyMiddleware runs an event through a middleware chain
+func applyMiddleware(chain []EventMiddleware, eventData EventData) (EventData, bool) {
+	for _, middleware := range chain {
+		var ok bool
+		if eventData, ok = middleware(eventData); !ok {
+			return eventData, false
+		}
+	}
+	return eventData, true
+}
+
+// FilterSymbols returns middleware that drops events for symbols not in the
+// list. Events without a symbol, such as tickerArr, pass through.
+func FilterSymbols(symbols ...string) EventMiddleware {
+	allowed := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		allowed[NormalizeSymbol(symbol)] = true
+	}
+
+	return func(e EventData) (EventData, bool) {
+		symbol := eventSymbol(e.Payload, e.Data)
+		return e, symbol == "" || allowed[strings.ToUpper(symbol)]
+	}
+}
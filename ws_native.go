@@ -0,0 +1,387 @@
+package pi42
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// errTransportClosed is passed to pending acknowledgements when the connection drops
+var errTransportClosed = errors.New("connection closed before acknowledgement")
+
+// nativeHandshake is the engine.io open packet payload
+type nativeHandshake struct {
+	Sid          string `json:"sid"`
+	PingInterval int64  `json:"pingInterval"` // Milliseconds
+	PingTimeout  int64  `json:"pingTimeout"`  // Milliseconds
+}
+
+// nativeTransport is a streamTransport that speaks engine.io v4 and
+// socket.io v5 framing over a single WebSocket, on the default namespace
+type nativeTransport struct {
+	url    string
+	onPing func()
+	dialer *websocket.Dialer
+
+	handlers    map[EventName][]func(...any)
+	anyHandlers []func(...any)
+
+	// Current connection, nil while disconnected
+	conn *websocket.Conn
+	// Whether the socket.io namespace is connected
+	connected bool
+	// Whether a connection attempt or read loop is running
+	running bool
+	// Reason reported when the current connection ends, if not a transport failure
+	closeReason string
+	// Error reported when the namespace connection was refused
+	connectErr error
+	// Read deadline extension granted by each heartbeat
+	heartbeat time.Duration
+	// Session ID of the namespace connection
+	id string
+	// Pending acknowledgement callbacks by packet ID
+	acks    map[uint64]func([]any, error)
+	nextAck uint64
+	mu      sync.Mutex
+	// gorilla/websocket allows one concurrent writer
+	writeMu sync.Mutex
+}
+
+// newNativeTransport creates a native connection to a socket.io WebSocket
+// endpoint. onPing is invoked for every heartbeat received from the server.
+// With compression, permessage-deflate is offered to the server and
+// compressed frames are decoded transparently.
+func newNativeTransport(url string, onPing func(), compression bool) *nativeTransport {
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = compression
+
+	return &nativeTransport{
+		url:      url,
+		onPing:   onPing,
+		dialer:   &dialer,
+		handlers: make(map[EventName][]func(...any)),
+		acks:     make(map[uint64]func([]any, error)),
+	}
+}
+
+func (t *nativeTransport) On(event EventName, handler func(...any)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers[event] = append(t.handlers[event], handler)
+}
+
+func (t *nativeTransport) OnAny(handler func(...any)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.anyHandlers = append(t.anyHandlers, handler)
+}
+
+func (t *nativeTransport) Connect() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running {
+		return
+	}
+	t.running = true
+	t.closeReason = ""
+	t.connectErr = nil
+	go t.run()
+}
+
+func (t *nativeTransport) Connected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.connected
+}
+
+func (t *nativeTransport) Disconnect() {
+	t.mu.Lock()
+	conn := t.conn
+	connected := t.connected
+	t.closeReason = "io client disconnect"
+	t.mu.Unlock()
+
+	if conn == nil {
+		return
+	}
+	if connected {
+		t.write("41")
+	}
+	conn.Close()
+}
+
+func (t *nativeTransport) Drop() {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+func (t *nativeTransport) Emit(event string, args ...any) error {
+	packet, err := json.Marshal(append([]any{event}, args...))
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %v", event, err)
+	}
+	return t.write("42" + string(packet))
+}
+
+func (t *nativeTransport) EmitWithAck(event string, ack func([]any, error), args ...any) {
+	packet, err := json.Marshal(append([]any{event}, args...))
+	if err != nil {
+		ack(nil, fmt.Errorf("error encoding %s event: %v", event, err))
+		return
+	}
+
+	t.mu.Lock()
+	id := t.nextAck
+	t.nextAck++
+	t.acks[id] = ack
+	t.mu.Unlock()
+
+	if err := t.write("42" + strconv.FormatUint(id, 10) + string(packet)); err != nil {
+		t.mu.Lock()
+		delete(t.acks, id)
+		t.mu.Unlock()
+		ack(nil, err)
+	}
+}
+
+func (t *nativeTransport) ID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.id
+}
+
+// write sends a text frame on the current connection
+func (t *nativeTransport) write(packet string) error {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	if conn == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	// Small control messages are not worth compressing
+	conn.EnableWriteCompression(len(packet) >= compressionThreshold)
+	return conn.WriteMessage(websocket.TextMessage, []byte(packet))
+}
+
+// emitLocal invokes the handlers registered for an event
+func (t *nativeTransport) emitLocal(event EventName, args ...any) {
+	t.mu.Lock()
+	handlers := append([]func(...any){}, t.handlers[event]...)
+	t.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(args...)
+	}
+}
+
+// run dials the server and reads packets until the connection ends
+func (t *nativeTransport) run() {
+	conn, _, err := t.dialer.Dial(t.url, nil)
+	if err != nil {
+		t.mu.Lock()
+		t.running = false
+		t.mu.Unlock()
+		t.emitLocal("connect_error", err)
+		return
+	}
+
+	t.mu.Lock()
+	t.conn = conn
+	disconnected := t.closeReason == "io client disconnect"
+	t.mu.Unlock()
+
+	// Disconnect was called while dialing
+	if disconnected {
+		conn.Close()
+	}
+
+	var readErr error
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			readErr = err
+			break
+		}
+		t.handlePacket(conn, string(data))
+	}
+	conn.Close()
+
+	t.mu.Lock()
+	wasConnected := t.connected
+	reason := t.closeReason
+	if t.connectErr != nil {
+		readErr = t.connectErr
+	}
+	acks := t.acks
+	t.conn = nil
+	t.connected = false
+	t.running = false
+	t.acks = make(map[uint64]func([]any, error))
+	t.mu.Unlock()
+
+	for _, ack := range acks {
+		ack(nil, errTransportClosed)
+	}
+
+	if reason == "" {
+		reason = "transport close"
+	}
+	switch {
+	case wasConnected:
+		t.emitLocal("disconnect", reason)
+	case reason != "io client disconnect":
+		t.emitLocal("connect_error", readErr)
+	}
+}
+
+// handlePacket processes an engine.io packet
+func (t *nativeTransport) handlePacket(conn *websocket.Conn, packet string) {
+	if packet == "" {
+		return
+	}
+
+	switch packet[0] {
+	case '0': // Open
+		var handshake nativeHandshake
+		if err := json.Unmarshal([]byte(packet[1:]), &handshake); err != nil {
+			logger().Warnf("Invalid engine.io handshake: %v", err)
+			conn.Close()
+			return
+		}
+		t.mu.Lock()
+		t.heartbeat = time.Duration(handshake.PingInterval+handshake.PingTimeout) * time.Millisecond
+		t.mu.Unlock()
+		t.extendDeadline(conn)
+
+		// Join the default namespace
+		if err := t.write("40"); err != nil {
+			conn.Close()
+		}
+	case '1': // Close
+		conn.Close()
+	case '2': // Ping
+		t.extendDeadline(conn)
+		t.write("3")
+		if t.onPing != nil {
+			t.onPing()
+		}
+	case '4': // Message
+		t.handleMessage(conn, packet[1:])
+	}
+}
+
+// handleMessage processes a socket.io packet carried by an engine.io message
+func (t *nativeTransport) handleMessage(conn *websocket.Conn, packet string) {
+	if packet == "" {
+		return
+	}
+	kind, body := packet[0], packet[1:]
+
+	// Packets for other namespaces are prefixed with the namespace
+	if strings.HasPrefix(body, "/") {
+		return
+	}
+
+	// An optional packet ID precedes the JSON payload
+	end := 0
+	for end < len(body) && body[end] >= '0' && body[end] <= '9' {
+		end++
+	}
+	idPart, payload := body[:end], body[end:]
+
+	switch kind {
+	case '0': // Connect
+		var connect struct {
+			Sid string `json:"sid"`
+		}
+		json.Unmarshal([]byte(payload), &connect)
+
+		t.mu.Lock()
+		t.connected = true
+		t.id = connect.Sid
+		t.mu.Unlock()
+		t.emitLocal("connect")
+	case '1': // Disconnect
+		t.mu.Lock()
+		t.closeReason = "io server disconnect"
+		t.mu.Unlock()
+		conn.Close()
+	case '2': // Event
+		var args []any
+		if err := json.Unmarshal([]byte(payload), &args); err != nil || len(args) == 0 {
+			logger().Warnf("Invalid socket.io event: %s", packet)
+			return
+		}
+		event, ok := args[0].(string)
+		if !ok {
+			return
+		}
+
+		t.mu.Lock()
+		anyHandlers := append([]func(...any){}, t.anyHandlers...)
+		t.mu.Unlock()
+		for _, handler := range anyHandlers {
+			handler(args...)
+		}
+		t.emitLocal(EventName(event), args[1:]...)
+	case '3': // Ack
+		id, err := strconv.ParseUint(idPart, 10, 64)
+		if err != nil {
+			return
+		}
+		var args []any
+		json.Unmarshal([]byte(payload), &args)
+
+		t.mu.Lock()
+		ack, ok := t.acks[id]
+		delete(t.acks, id)
+		t.mu.Unlock()
+		if ok {
+			ack(args, nil)
+		}
+	case '4': // Connect error
+		var connectErr struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal([]byte(payload), &connectErr)
+
+		t.mu.Lock()
+		t.connectErr = fmt.Errorf("namespace connection refused: %s", connectErr.Message)
+		t.mu.Unlock()
+		conn.Close()
+	}
+}
+
+// extendDeadline allows the server one more heartbeat period before the
+// connection is considered dead
+func (t *nativeTransport) extendDeadline(conn *websocket.Conn) {
+	t.mu.Lock()
+	heartbeat := t.heartbeat
+	t.mu.Unlock()
+
+	if heartbeat > 0 {
+		conn.SetReadDeadline(time.Now().Add(heartbeat))
+	}
+}
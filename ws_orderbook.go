@@ -0,0 +1,261 @@
+package pi42
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxBufferedDepthUpdates bounds the updates held while a snapshot is loaded
+const maxBufferedDepthUpdates = 1000
+
+// depthSnapshotRetryDelay is how long to wait before requesting a new
+// snapshot when the buffered updates don't line up with the last one
+const depthSnapshotRetryDelay = 500 * time.Millisecond
+
+// OrderBookUpdate describes the top of a live order book after a change
+type OrderBookUpdate struct {
+	Symbol       string
+	BestBid      PriceLevel // Zero if the bid side is empty
+	BestAsk      PriceLevel // Zero if the ask side is empty
+	LastUpdateID int64
+	Time         time.Time
+	// Whether the book was rebuilt from a fresh snapshot
+	Resynced bool
+}
+
+// OrderBookSnapshot is a point-in-time copy of a live order book
+type OrderBookSnapshot struct {
+	Symbol       string
+	Bids         []PriceLevel // Best bid first
+	Asks         []PriceLevel // Best ask first
+	LastUpdateID int64
+	UpdatedAt    time.Time
+}
+
+// LiveOrderBook is a local order book kept in sync with the depth stream.
+// It loads a REST snapshot, applies buffered and live updates in sequence
+// and reloads the snapshot whenever a gap in the update IDs is detected.
+type LiveOrderBook struct {
+	Symbol string
+
+	sc     *SocketClient
+	market *MarketAPI
+	topic  string
+	book   *OrderBook
+
+	// Whether the book is built on a snapshot and updates are applied directly
+	synced bool
+	// Whether a snapshot request is in flight
+	loading bool
+	// Updates received while the snapshot is loading
+	pending []DepthData
+	// Channel receiving the top of the book after every change
+	updates chan OrderBookUpdate
+	closed  bool
+	// Mutex for thread-safe access to the sync state and updates channel
+	mu sync.Mutex
+}
+
+// SubscribeOrderBook subscribes to a symbol's depth stream and returns a
+// live order book. Snapshot loading and update sequencing are handled
+// internally. The price grouping is set with SetDepthGrouping.
+func (sc *SocketClient) SubscribeOrderBook(symbol string) *LiveOrderBook {
+	sc.channelMutex.Lock()
+	if sc.market == nil {
+		client := &Client{
+			PublicURL:  "https://api.pi42.com",
+			HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		}
+		client.Symbols = NewSymbolResolver(client)
+		sc.market = NewMarketAPI(client)
+	}
+	market := sc.market
+	sc.channelMutex.Unlock()
+
+	lb := &LiveOrderBook{
+		Symbol:  symbol,
+		sc:      sc,
+		market:  market,
+		topic:   DepthTopic(symbol, sc.depthGrouping(symbol)),
+		book:    NewOrderBook(symbol),
+		updates: make(chan OrderBookUpdate, defaultChannelBufferSize),
+	}
+	sc.onTopic(lb.topic, "depthUpdate", func(e EventData) {
+		if depth, ok := e.Payload.(*DepthUpdateEvent); ok {
+			lb.apply(depth.DepthData)
+		}
+	})
+	return lb
+}
+
+// Updates returns the channel receiving the top of the book after every
+// change. When the consumer falls behind, the oldest updates are dropped.
+func (lb *LiveOrderBook) Updates() <-chan OrderBookUpdate {
+	return lb.updates
+}
+
+// BestBid returns the highest bid level
+func (lb *LiveOrderBook) BestBid() (PriceLevel, bool) {
+	return lb.book.BestBid()
+}
+
+// BestAsk returns the lowest ask level
+func (lb *LiveOrderBook) BestAsk() (PriceLevel, bool) {
+	return lb.book.BestAsk()
+}
+
+// Synced reports whether the book has been built from a snapshot
+func (lb *LiveOrderBook) Synced() bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	return lb.synced
+}
+
+// Snapshot returns up to levels price levels per side; levels <= 0 returns all
+func (lb *LiveOrderBook) Snapshot(levels int) OrderBookSnapshot {
+	return OrderBookSnapshot{
+		Symbol:       lb.Symbol,
+		Bids:         lb.book.Bids(levels),
+		Asks:         lb.book.Asks(levels),
+		LastUpdateID: lb.book.LastUpdateID(),
+		UpdatedAt:    lb.book.UpdatedAt(),
+	}
+}
+
+// Book returns the underlying order book, e.g. to compute Metrics
+func (lb *LiveOrderBook) Book() *OrderBook {
+	return lb.book
+}
+
+// Close unsubscribes from the depth stream and closes the updates channel
+func (lb *LiveOrderBook) Close() {
+	lb.sc.Unsubscribe(lb.topic)
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if !lb.closed {
+		lb.closed = true
+		close(lb.updates)
+	}
+}
+
+// apply sequences a depth update against the book, loading a snapshot when
+// the book is not synced or an update was missed
+func (lb *LiveOrderBook) apply(depth DepthData) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if lb.closed {
+		return
+	}
+
+	if lb.synced {
+		last := lb.book.LastUpdateID()
+		if inDepthSequence(depth, last) {
+			if err := lb.book.ApplyUpdate(depth); err != nil {
+				logger().Warnf("Could not apply depth update for %s: %v", lb.Symbol, err)
+				return
+			}
+			lb.notify(false)
+			return
+		}
+		logger().Warnf("Depth gap for %s (expected %d, got %d); reloading snapshot", lb.Symbol, last, depth.PrevUpdateID)
+		lb.synced = false
+		lb.pending = nil
+	}
+
+	if len(lb.pending) < maxBufferedDepthUpdates {
+		lb.pending = append(lb.pending, depth)
+	}
+	if !lb.loading {
+		lb.loading = true
+		go lb.loadSnapshot()
+	}
+}
+
+// inDepthSequence reports whether an update follows the book's last update
+// ID without a gap. Updates without sequence IDs, or already contained in the
+// book, are treated as in sequence.
+func inDepthSequence(depth DepthData, last int64) bool {
+	if last == 0 || depth.LastUpdateID <= last {
+		return true
+	}
+	if depth.PrevUpdateID == 0 && depth.FirstUpdateID == 0 {
+		return true
+	}
+	// The first update after a snapshot straddles the snapshot's ID
+	return depth.PrevUpdateID == last || (depth.FirstUpdateID > 0 && depth.FirstUpdateID <= last+1)
+}
+
+// loadSnapshot fetches a REST snapshot and replays the buffered updates on
+// top of it. If the buffered updates don't continue from the snapshot, a
+// new snapshot is requested instead of marking the book synced.
+func (lb *LiveOrderBook) loadSnapshot() {
+	response, err := lb.market.getDepth(lb.Symbol, 0, false)
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	lb.loading = false
+	if lb.closed {
+		return
+	}
+	if err != nil {
+		// The next update triggers another attempt
+		logger().Warnf("Could not load depth snapshot for %s: %v", lb.Symbol, err)
+		lb.pending = nil
+		return
+	}
+	if err := lb.book.ApplySnapshot(response.Data); err != nil {
+		logger().Warnf("Could not apply depth snapshot for %s: %v", lb.Symbol, err)
+		lb.pending = nil
+		return
+	}
+
+	// ApplyUpdate skips updates already contained in the snapshot
+	for i, depth := range lb.pending {
+		last := lb.book.LastUpdateID()
+		if !inDepthSequence(depth, last) {
+			logger().Warnf("Depth gap for %s after snapshot (expected %d, got %d); reloading snapshot",
+				lb.Symbol, last, depth.PrevUpdateID)
+			lb.pending = lb.pending[i:]
+			lb.loading = true
+			time.AfterFunc(depthSnapshotRetryDelay, lb.loadSnapshot)
+			return
+		}
+		if err := lb.book.ApplyUpdate(depth); err != nil {
+			logger().Warnf("Could not apply depth update for %s: %v", lb.Symbol, err)
+		}
+	}
+	lb.pending = nil
+	lb.synced = true
+	lb.notify(true)
+}
+
+// notify sends the top of the book to the updates channel, dropping the
+// oldest update if the consumer is behind. The caller must hold lb.mu.
+func (lb *LiveOrderBook) notify(resynced bool) {
+	update := OrderBookUpdate{
+		Symbol:       lb.Symbol,
+		LastUpdateID: lb.book.LastUpdateID(),
+		Time:         lb.book.UpdatedAt(),
+		Resynced:     resynced,
+	}
+	update.BestBid, _ = lb.book.BestBid()
+	update.BestAsk, _ = lb.book.BestAsk()
+
+	for {
+		select {
+		case lb.updates <- update:
+			return
+		default:
+		}
+		select {
+		case <-lb.updates:
+		default:
+		}
+	}
+}
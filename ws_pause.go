@@ -0,0 +1,134 @@
+package pi42
+
+import (
+	"slices"
+	"time"
+)
+
+// pausePollInterval is how often a paused topic's backlog is checked
+const pausePollInterval = 100 * time.Millisecond
+
+// OnSlowConsumer registers a callback invoked when a topic is paused because
+// its channel backlog reached ChannelConfig.PauseAt, and again with paused
+// set to false when it is resumed
+func (sc *SocketClient) OnSlowConsumer(callback func(topic string, paused bool)) {
+	sc.pauseMutex.Lock()
+	defer sc.pauseMutex.Unlock()
+
+	sc.onSlowConsumer = append(sc.onSlowConsumer, callback)
+}
+
+// PausedTopics returns the topics currently paused for slow consumers
+func (sc *SocketClient) PausedTopics() []string {
+	sc.pauseMutex.Lock()
+	defer sc.pauseMutex.Unlock()
+
+	topics := make([]string, 0, len(sc.pausedTopics))
+	for topic := range sc.pausedTopics {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+	return topics
+}
+
+// isPaused reports whether delivery of a topic is paused
+func (sc *SocketClient) isPaused(topic string) bool {
+	sc.pauseMutex.Lock()
+	defer sc.pauseMutex.Unlock()
+
+	return sc.pausedTopics[topic]
+}
+
+// checkBacklog pauses a topic whose channel backlog reached the configured
+// threshold. The caller must hold channelMutex.
+func (sc *SocketClient) checkBacklog(topic string, ch chan EventData, config ChannelConfig) {
+	if config.PauseAt <= 0 || len(ch) < config.PauseAt {
+		return
+	}
+
+	sc.pauseMutex.Lock()
+	if sc.pausedTopics[topic] {
+		sc.pauseMutex.Unlock()
+		return
+	}
+	sc.pausedTopics[topic] = true
+	sc.pauseMutex.Unlock()
+
+	// Unsubscribing takes subscriptionMutex, which precedes channelMutex
+	go sc.pauseTopic(topic, ch, config.ResumeAt)
+}
+
+// pauseTopic unsubscribes a topic upstream and resubscribes it once the
+// consumer has drained its channel to resumeAt messages
+func (sc *SocketClient) pauseTopic(topic string, ch chan EventData, resumeAt int) {
+	sc.subscriptionMutex.Lock()
+	if !sc.ownsChannel(topic, ch) {
+		sc.subscriptionMutex.Unlock()
+		sc.clearPause(topic)
+		return
+	}
+	if sc.io != nil && sc.io.Connected() {
+		sc.io.Emit("unsubscribe", map[string][]string{
+			"params": {topic},
+		})
+	}
+	sc.subscriptionMutex.Unlock()
+
+	logger().Warnf("Paused %s: %d messages backlogged", topic, len(ch))
+	sc.notifySlowConsumer(topic, true)
+
+	ticker := time.NewTicker(pausePollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if len(ch) > resumeAt {
+			sc.channelMutex.RLock()
+			owned := !sc.closed && sc.topicChannels[topic] == ch
+			sc.channelMutex.RUnlock()
+			if owned {
+				continue
+			}
+		}
+		break
+	}
+
+	sc.subscriptionMutex.Lock()
+	resume := sc.ownsChannel(topic, ch)
+	sc.clearPause(topic)
+	if resume && sc.io != nil && sc.io.Connected() {
+		emitSubscribe(sc, []string{topic}, nil)
+	}
+	sc.subscriptionMutex.Unlock()
+
+	if resume {
+		logger().Infof("Resumed %s", topic)
+		sc.notifySlowConsumer(topic, false)
+	}
+}
+
+// ownsChannel reports whether topic is still subscribed with channel ch
+func (sc *SocketClient) ownsChannel(topic string, ch chan EventData) bool {
+	sc.channelMutex.RLock()
+	defer sc.channelMutex.RUnlock()
+
+	return !sc.closed && sc.topicChannels[topic] == ch && slices.Contains(sc.topics, topic)
+}
+
+// clearPause marks a topic as no longer paused
+func (sc *SocketClient) clearPause(topic string) {
+	sc.pauseMutex.Lock()
+	defer sc.pauseMutex.Unlock()
+
+	delete(sc.pausedTopics, topic)
+}
+
+// notifySlowConsumer invokes the slow consumer callbacks
+func (sc *SocketClient) notifySlowConsumer(topic string, paused bool) {
+	sc.pauseMutex.Lock()
+	callbacks := append([]func(string, bool){}, sc.onSlowConsumer...)
+	sc.pauseMutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback(topic, paused)
+	}
+}
@@ -0,0 +1,147 @@
+package pi42
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrReconnectGaveUp is returned by Connect when the reconnect policy's
+// attempts are exhausted before a connection could be established
+var ErrReconnectGaveUp = errors.New("websocket reconnect attempts exhausted")
+
+// ConnectionState describes the state of the WebSocket connection
+type ConnectionState int
+
+// Connection states reported to OnStateChange callbacks
+const (
+	// StateDisconnected means the connection is down and no attempt is pending
+	StateDisconnected ConnectionState = iota
+	// StateConnecting means a connection or reconnection attempt is in progress
+	StateConnecting
+	// StateConnected means the connection is established
+	StateConnected
+	// StateGaveUp means the reconnect policy's attempts are exhausted
+	StateGaveUp
+)
+
+// String returns the name of the state
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateGaveUp:
+		return "gave up"
+	}
+	return "unknown"
+}
+
+// ReconnectPolicy controls how the client reconnects after the connection
+// drops or an attempt fails. The delay before attempt n is
+// InitialDelay * Multiplier^(n-1), capped at MaxDelay.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration // Delay before the first attempt
+	Multiplier   float64       // Growth factor of the delay; values below 1 are treated as 1
+	MaxDelay     time.Duration // Upper bound of the delay; 0 means no bound
+	MaxAttempts  int           // Consecutive failed attempts before giving up; 0 retries forever
+}
+
+// DefaultReconnectPolicy returns the policy used when none was set
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialDelay: time.Second,
+		Multiplier:   2,
+		MaxDelay:     30 * time.Second,
+	}
+}
+
+// delay returns the wait before the given attempt, starting at 1
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	multiplier := max(p.Multiplier, 1)
+	delay := float64(p.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// SetReconnectPolicy sets how the client reconnects. Must be called before Connect.
+func (sc *SocketClient) SetReconnectPolicy(policy ReconnectPolicy) {
+	sc.stateMutex.Lock()
+	defer sc.stateMutex.Unlock()
+
+	sc.reconnectPolicy = policy
+}
+
+// OnStateChange registers a callback invoked whenever the connection state
+// changes, e.g. to alert or pause trading while the feed is down
+func (sc *SocketClient) OnStateChange(callback func(state ConnectionState)) {
+	sc.stateMutex.Lock()
+	defer sc.stateMutex.Unlock()
+
+	sc.stateCallbacks = append(sc.stateCallbacks, callback)
+}
+
+// State returns the current connection state
+func (sc *SocketClient) State() ConnectionState {
+	sc.stateMutex.Lock()
+	defer sc.stateMutex.Unlock()
+
+	return sc.state
+}
+
+// setState records a state change and notifies the callbacks
+func (sc *SocketClient) setState(state ConnectionState) {
+	sc.stateMutex.Lock()
+	if sc.state == state {
+		sc.stateMutex.Unlock()
+		return
+	}
+	sc.state = state
+	if state == StateConnected {
+		sc.reconnectAttempts = 0
+	}
+	callbacks := append([]func(ConnectionState){}, sc.stateCallbacks...)
+	sc.stateMutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback(state)
+	}
+}
+
+// scheduleReconnect starts the next reconnect attempt after the policy's
+// delay. It returns false if the policy gave up.
+func (sc *SocketClient) scheduleReconnect(ctx context.Context, io streamTransport) bool {
+	sc.stateMutex.Lock()
+	sc.reconnectAttempts++
+	attempt := sc.reconnectAttempts
+	policy := sc.reconnectPolicy
+	sc.stateMutex.Unlock()
+
+	if policy.MaxAttempts > 0 && attempt > policy.MaxAttempts {
+		logger().Warnf("Giving up after %d reconnect attempts", policy.MaxAttempts)
+		sc.setState(StateGaveUp)
+		return false
+	}
+
+	delay := policy.delay(attempt)
+	logger().Infof("Reconnect attempt %d in %v", attempt, delay)
+	sc.setState(StateConnecting)
+
+	time.AfterFunc(delay, func() {
+		if ctx.Err() == nil && !io.Connected() {
+			io.Connect()
+		}
+	})
+	return true
+}
@@ -0,0 +1,156 @@
+package pi42
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxRecordedLineSize bounds a single line of a recording, large enough for
+// tickerArr and allContractDetails payloads
+const maxRecordedLineSize = 16 * 1024 * 1024
+
+// RecordedEvent is one line of a stream recording
+type RecordedEvent struct {
+	Event    EventName       `json:"event"`
+	Received time.Time       `json:"received"`
+	Args     json.RawMessage `json:"args"` // JSON array of the event arguments
+}
+
+// StreamRecorder writes every received event with its arrival time to a
+// writer as JSON lines. Install it as the raw tap:
+//
+//	recorder := pi42.NewStreamRecorder(file)
+//	client.SetRawTap(recorder.Record)
+type StreamRecorder struct {
+	w   *bufio.Writer
+	err error
+	mu  sync.Mutex
+}
+
+// NewStreamRecorder creates a recorder writing to w
+func NewStreamRecorder(w io.Writer) *StreamRecorder {
+	return &StreamRecorder{w: bufio.NewWriter(w)}
+}
+
+// Record appends a message to the recording. After a write error further
+// messages are discarded; the error is returned by Flush.
+func (r *StreamRecorder) Record(msg RawMessage) {
+	line, err := json.Marshal(RecordedEvent{
+		Event:    msg.Event,
+		Received: msg.Received,
+		Args:     msg.Payload,
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	if err != nil {
+		r.err = fmt.Errorf("error encoding %s event: %v", msg.Event, err)
+		return
+	}
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		r.err = fmt.Errorf("error writing recording: %v", err)
+	}
+}
+
+// Flush writes buffered events to the underlying writer and returns the
+// first error encountered while recording
+func (r *StreamRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if err := r.w.Flush(); err != nil {
+		r.err = fmt.Errorf("error writing recording: %v", err)
+	}
+	return r.err
+}
+
+// StreamPlayer replays a recording made by StreamRecorder into a
+// SocketClient, which delivers the events to its channels and callbacks as
+// if they were received from the server
+type StreamPlayer struct {
+	r     io.Reader
+	speed float64
+}
+
+// NewStreamPlayer creates a player reading a recording from r. Events are
+// replayed at their original pace until SetSpeed is called.
+func NewStreamPlayer(r io.Reader) *StreamPlayer {
+	return &StreamPlayer{r: r, speed: 1}
+}
+
+// SetSpeed sets the playback speed relative to the original pace, e.g. 10
+// replays ten times faster. A speed of 0 replays without waiting.
+func (p *StreamPlayer) SetSpeed(speed float64) {
+	p.speed = speed
+}
+
+// Play replays the recording into sc and returns when the recording ends or
+// ctx is cancelled. The client should not be connected; subscribe to the
+// recorded topics beforehand so events are routed to their topic channels.
+func (p *StreamPlayer) Play(ctx context.Context, sc *SocketClient) error {
+	handlers := make(map[EventName]func(...any), len(sc.events))
+	for _, event := range sc.events {
+		handlers[event] = createChannelEventHandler(sc, event)
+	}
+
+	// The worker pool is normally started by Connect
+	sc.channelMutex.RLock()
+	if sc.pool != nil && sc.io == nil {
+		sc.pool.start(ctx, sc)
+	}
+	sc.channelMutex.RUnlock()
+
+	scanner := bufio.NewScanner(p.r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordedLineSize)
+
+	var previous time.Time
+	for line := 1; scanner.Scan(); line++ {
+		var recorded RecordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return fmt.Errorf("error parsing recording line %d: %v", line, err)
+		}
+
+		if p.speed > 0 && !previous.IsZero() {
+			if wait := recorded.Received.Sub(previous); wait > 0 {
+				timer := time.NewTimer(time.Duration(float64(wait) / p.speed))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+		}
+		previous = recorded.Received
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		handler, ok := handlers[recorded.Event]
+		if !ok {
+			continue
+		}
+		var args []any
+		if err := json.Unmarshal(recorded.Args, &args); err != nil {
+			return fmt.Errorf("error parsing %s arguments on line %d: %v", recorded.Event, line, err)
+		}
+		handler(args...)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading recording: %v", err)
+	}
+	return nil
+}
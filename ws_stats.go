@@ -0,0 +1,175 @@
+package pi42
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventStats summarizes the messages received for an event type
+type EventStats struct {
+	Messages         uint64        // Number of messages received
+	Rate             float64       // Messages per second since the first message
+	AvgDecodeLatency time.Duration // Average time spent decoding the payload
+	MaxDecodeLatency time.Duration // Slowest payload decode
+	AvgReceiveDelay  time.Duration // Average delay between the event time and receipt
+	MaxReceiveDelay  time.Duration // Largest delay between the event time and receipt
+	LastReceived     time.Time     // Time of the latest message
+}
+
+// eventMetrics accumulates the raw measurements behind EventStats
+type eventMetrics struct {
+	messages     uint64
+	firstSeen    time.Time
+	lastSeen     time.Time
+	decodeTotal  time.Duration
+	decodeMax    time.Duration
+	delayTotal   time.Duration
+	delayMax     time.Duration
+	delaySamples uint64
+}
+
+// metricsRecorder collects per-event-type message metrics
+type metricsRecorder struct {
+	events map[EventName]*eventMetrics
+	mu     sync.Mutex
+}
+
+// newMetricsRecorder creates an empty metrics recorder
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{events: make(map[EventName]*eventMetrics)}
+}
+
+// record adds a received message to the metrics. eventTime is the
+// exchange-side event time in milliseconds, or 0 if unknown.
+func (r *metricsRecorder) record(event EventName, received time.Time, decode time.Duration, eventTime int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.events[event]
+	if !ok {
+		m = &eventMetrics{firstSeen: received}
+		r.events[event] = m
+	}
+	m.messages++
+	m.lastSeen = received
+	m.decodeTotal += decode
+	m.decodeMax = max(m.decodeMax, decode)
+
+	if eventTime > 0 {
+		// Clock skew can make the delay negative; count it as zero
+		delay := max(received.Sub(time.UnixMilli(eventTime)), 0)
+		m.delayTotal += delay
+		m.delayMax = max(m.delayMax, delay)
+		m.delaySamples++
+	}
+}
+
+// Stats returns a snapshot of message metrics per event type
+func (sc *SocketClient) Stats() map[EventName]EventStats {
+	r := sc.metrics
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[EventName]EventStats, len(r.events))
+	for event, m := range r.events {
+		s := EventStats{
+			Messages:         m.messages,
+			MaxDecodeLatency: m.decodeMax,
+			MaxReceiveDelay:  m.delayMax,
+			LastReceived:     m.lastSeen,
+		}
+		if m.messages > 0 {
+			s.AvgDecodeLatency = m.decodeTotal / time.Duration(m.messages)
+		}
+		if m.delaySamples > 0 {
+			s.AvgReceiveDelay = m.delayTotal / time.Duration(m.delaySamples)
+		}
+		if elapsed := m.lastSeen.Sub(m.firstSeen).Seconds(); elapsed > 0 {
+			s.Rate = float64(m.messages) / elapsed
+		}
+		stats[event] = s
+	}
+	return stats
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition
+// format, so they can be served from a /metrics handler without adding a
+// Prometheus client dependency
+func (sc *SocketClient) WritePrometheus(w io.Writer) error {
+	stats := sc.Stats()
+
+	events := make([]string, 0, len(stats))
+	for event := range stats {
+		events = append(events, string(event))
+	}
+	sort.Strings(events)
+
+	metrics := []struct {
+		name  string
+		help  string
+		kind  string
+		value func(EventStats) float64
+	}{
+		{"pi42_ws_messages_total", "WebSocket messages received.", "counter",
+			func(s EventStats) float64 { return float64(s.Messages) }},
+		{"pi42_ws_decode_latency_seconds_avg", "Average payload decode latency.", "gauge",
+			func(s EventStats) float64 { return s.AvgDecodeLatency.Seconds() }},
+		{"pi42_ws_decode_latency_seconds_max", "Maximum payload decode latency.", "gauge",
+			func(s EventStats) float64 { return s.MaxDecodeLatency.Seconds() }},
+		{"pi42_ws_receive_delay_seconds_avg", "Average delay between event time and receipt.", "gauge",
+			func(s EventStats) float64 { return s.AvgReceiveDelay.Seconds() }},
+		{"pi42_ws_receive_delay_seconds_max", "Maximum delay between event time and receipt.", "gauge",
+			func(s EventStats) float64 { return s.MaxReceiveDelay.Seconds() }},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, event := range events {
+			value := metric.value(stats[EventName(event)])
+			if _, err := fmt.Fprintf(w, "%s{event=%q} %g\n", metric.name, event, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	heartbeat := sc.HeartbeatStats()
+	heartbeatMetrics := []struct {
+		name  string
+		help  string
+		kind  string
+		value float64
+	}{
+		{"pi42_ws_pings_total", "Heartbeat pings received from the server.", "counter", float64(heartbeat.Pings)},
+		{"pi42_ws_ping_interval_seconds_avg", "Average time between server pings.", "gauge", heartbeat.AvgInterval.Seconds()},
+		{"pi42_ws_ping_interval_seconds_max", "Longest time between server pings.", "gauge", heartbeat.MaxInterval.Seconds()},
+		{"pi42_ws_missed_heartbeats_total", "Heartbeat timeouts detected.", "counter", float64(heartbeat.Missed)},
+	}
+	for _, metric := range heartbeatMetrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// payloadEventTime returns the exchange-side event time of a decoded payload in milliseconds
+func payloadEventTime(payload any) int64 {
+	switch p := payload.(type) {
+	case *DepthUpdateEvent:
+		return p.EventTime
+	case *MarkPriceEvent:
+		return p.EventTime
+	case *KlineEvent:
+		return p.EventTime
+	case *TickerEvent:
+		return p.EventTime
+	case *AggTradeEvent:
+		return p.EventTime
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package pi42
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RawMessage is a Socket.IO event as received, before typed decoding
+type RawMessage struct {
+	Event    EventName
+	Payload  []byte // JSON encoding of the event arguments
+	Received time.Time
+}
+
+// SetRawTap registers a hook that receives every incoming event, including
+// events the client has no channel for, before it is decoded. It is meant
+// for debugging, protocol recording and building replay fixtures. Pass nil
+// to remove the tap.
+func (sc *SocketClient) SetRawTap(tap func(RawMessage)) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.rawTap = tap
+}
+
+// handleRawMessage passes an incoming event to the raw tap, if one is set
+func (sc *SocketClient) handleRawMessage(args ...any) {
+	sc.channelMutex.RLock()
+	tap := sc.rawTap
+	sc.channelMutex.RUnlock()
+
+	if tap == nil || len(args) == 0 {
+		return
+	}
+
+	event, ok := args[0].(string)
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(args[1:])
+	if err != nil {
+		logger().Warnf("Could not encode raw %s message: %v", event, err)
+		return
+	}
+
+	tap(RawMessage{
+		Event:    EventName(event),
+		Payload:  payload,
+		Received: time.Now(),
+	})
+}
@@ -0,0 +1,161 @@
+package pi42
+
+import (
+	"fmt"
+	"strings"
+)
+
+// topicChannelEvents maps the channel part of a topic to the event it produces
+var topicChannelEvents = map[string]EventName{
+	"depth":     "depthUpdate",
+	"markPrice": "markPriceUpdate",
+	"kline":     "kline",
+	"aggTrade":  "aggTrade",
+	"trade":     "aggTrade",
+	"ticker":    "24hrTicker",
+}
+
+// splitTopic splits a topic of the form <symbol>@<channel>_<options> into its parts
+func splitTopic(topic string) (symbol, channel, options string) {
+	symbol, stream, found := strings.Cut(topic, "@")
+	if !found {
+		return "", topic, ""
+	}
+	channel, options, _ = strings.Cut(stream, "_")
+	return strings.ToLower(symbol), channel, options
+}
+
+// TopicEvent returns the event name delivered for a topic
+func TopicEvent(topic string) (EventName, bool) {
+	_, channel, _ := splitTopic(topic)
+	event, ok := topicChannelEvents[channel]
+	return event, ok
+}
+
+// eventTopicChannels maps events back to the channel used to build their topic
+var eventTopicChannels = map[EventName]string{
+	"depthUpdate":     "depth",
+	"markPriceUpdate": "markPrice",
+	"kline":           "kline",
+	"aggTrade":        "aggTrade",
+	"24hrTicker":      "ticker",
+}
+
+// eventSymbol returns the symbol an event refers to, taken from the decoded
+// payload or, for events without a typed payload, from the raw "s" field
+func eventSymbol(payload any, data []any) string {
+	switch p := payload.(type) {
+	case *DepthUpdateEvent:
+		return p.Symbol
+	case *MarkPriceEvent:
+		return p.Symbol
+	case *KlineEvent:
+		return p.Symbol
+	case *TickerEvent:
+		return p.Symbol
+	case *AggTradeEvent:
+		return p.Symbol
+	}
+
+	if len(data) > 0 {
+		if m, ok := data[0].(map[string]interface{}); ok {
+			if symbol, ok := m["s"].(string); ok {
+				return symbol
+			}
+		}
+	}
+	return ""
+}
+
+// resolveTopic determines the topic that produced an event. A matching
+// subscribed topic is preferred; otherwise the topic is derived from the
+// symbol and event. Events without a symbol, such as tickerArr, have no topic.
+func resolveTopic(topics []string, event EventName, payload any, data []any) string {
+	symbol := strings.ToLower(eventSymbol(payload, data))
+	if symbol == "" {
+		return ""
+	}
+
+	var interval string
+	if kline, ok := payload.(*KlineEvent); ok {
+		interval = kline.Kline.Interval
+	}
+
+	for _, topic := range topics {
+		topicSymbol, channel, options := splitTopic(topic)
+		if topicSymbol != symbol || topicChannelEvents[channel] != event {
+			continue
+		}
+		if interval != "" && options != "" && strings.TrimSpace(options) != interval {
+			continue
+		}
+		return topic
+	}
+
+	channel, ok := eventTopicChannels[event]
+	if !ok {
+		channel = string(event)
+	}
+	if interval != "" {
+		return symbol + "@" + channel + "_" + interval
+	}
+	return symbol + "@" + channel
+}
+
+// defaultDepthGrouping is the depth grouping used when none was configured for a symbol
+const defaultDepthGrouping = "0.1"
+
+// DepthTopic builds the order book topic for a symbol and price grouping
+func DepthTopic(symbol, grouping string) string {
+	return fmt.Sprintf("%s@depth_%s", strings.ToLower(NormalizeSymbol(symbol)), grouping)
+}
+
+// MarkPriceTopic builds the mark price topic for a symbol
+func MarkPriceTopic(symbol string) string {
+	return fmt.Sprintf("%s@markPrice", strings.ToLower(NormalizeSymbol(symbol)))
+}
+
+// KlineTopic builds the kline topic for a symbol and interval
+func KlineTopic(symbol string, interval KlineInterval) string {
+	return fmt.Sprintf("%s@kline_%s", strings.ToLower(NormalizeSymbol(symbol)), interval)
+}
+
+// TickerTopic builds the 24hr ticker topic for a symbol
+func TickerTopic(symbol string) string {
+	return fmt.Sprintf("%s@ticker", strings.ToLower(NormalizeSymbol(symbol)))
+}
+
+// AggTradeTopic builds the aggregated trade topic for a symbol
+func AggTradeTopic(symbol string) string {
+	return fmt.Sprintf("%s@aggTrade", strings.ToLower(NormalizeSymbol(symbol)))
+}
+
+// SymbolStream is a per-symbol stream that AddSymbolStreams expands into a topic
+type SymbolStream string
+
+// Per-symbol streams. Kline streams for other intervals are built with StreamKline.
+const (
+	StreamDepth     SymbolStream = "depth" // Uses the symbol's configured depth grouping
+	StreamMarkPrice SymbolStream = "markPrice"
+	StreamTicker    SymbolStream = "ticker"
+	StreamAggTrade  SymbolStream = "aggTrade"
+	StreamKline1m   SymbolStream = "kline_1m"
+	StreamKline5m   SymbolStream = "kline_5m"
+	StreamKline15m  SymbolStream = "kline_15m"
+	StreamKline1h   SymbolStream = "kline_1h"
+	StreamKline4h   SymbolStream = "kline_4h"
+	StreamKline1d   SymbolStream = "kline_1d"
+)
+
+// StreamKline returns the kline stream for an interval
+func StreamKline(interval KlineInterval) SymbolStream {
+	return SymbolStream("kline_" + string(interval))
+}
+
+// symbolTopic builds the topic of a stream for a symbol, e.g. btcinr@kline_1m
+func (sc *SocketClient) symbolTopic(symbol string, stream SymbolStream) string {
+	if stream == StreamDepth {
+		return DepthTopic(symbol, sc.depthGrouping(symbol))
+	}
+	return strings.ToLower(NormalizeSymbol(symbol)) + "@" + string(stream)
+}
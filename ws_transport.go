@@ -0,0 +1,71 @@
+package pi42
+
+// Transport selects the implementation used by SocketClient to talk to the
+// stream server
+type Transport int
+
+// Supported transports
+const (
+	// TransportSocketIO uses the socket.io client library. It is not
+	// available in builds with the pi42_nosocketio tag, which leave the
+	// library out of the binary.
+	TransportSocketIO Transport = iota
+	// TransportNative speaks the engine.io/socket.io framing directly over a
+	// single WebSocket connection. It has a much smaller footprint but does
+	// not support HTTP long-polling or binary attachments.
+	TransportNative
+)
+
+// streamTransport is a socket.io connection as used by SocketClient. Besides
+// server events, "connect", "connect_error" and "disconnect" are delivered
+// to the On handlers; "disconnect" receives the reason as first argument.
+type streamTransport interface {
+	// On registers a handler for an event
+	On(event EventName, handler func(...any))
+	// OnAny registers a handler receiving every server event, with the event
+	// name as first argument
+	OnAny(handler func(...any))
+	// Connect starts connecting in the background
+	Connect()
+	// Connected reports whether the connection is established
+	Connected() bool
+	// Disconnect closes the connection at the client's request
+	Disconnect()
+	// Drop closes the underlying connection as if it failed, so it is
+	// reported with a transport error reason and retried
+	Drop()
+	// Emit sends an event
+	Emit(event string, args ...any) error
+	// EmitWithAck sends an event and invokes ack with the server's acknowledgement
+	EmitWithAck(event string, ack func([]any, error), args ...any)
+	// ID returns the session ID of the current connection
+	ID() string
+}
+
+// compressionThreshold is the size from which outgoing messages are
+// compressed when permessage-deflate is negotiated
+const compressionThreshold = 1024
+
+// SetTransport selects the transport used by Connect. The default is
+// TransportSocketIO, or TransportNative in builds with the pi42_nosocketio
+// tag. Must be called before Connect.
+func (sc *SocketClient) SetTransport(transport Transport) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.transport = transport
+}
+
+// SetCompression controls whether permessage-deflate compression is
+// negotiated with the server. It is enabled by default and cuts bandwidth
+// considerably on full-depth and all-symbol streams, at the cost of CPU
+// time to decompress frames. Must be called before Connect.
+//
+// The socket.io library cannot turn compression off, so disabling it with
+// TransportSocketIO selected makes Connect use TransportNative instead.
+func (sc *SocketClient) SetCompression(enabled bool) {
+	sc.channelMutex.Lock()
+	defer sc.channelMutex.Unlock()
+
+	sc.compression = enabled
+}
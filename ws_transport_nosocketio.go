@@ -0,0 +1,28 @@
+//go:build pi42_nosocketio
+
+package pi42
+
+import (
+	"errors"
+	"strings"
+)
+
+// defaultTransport is the transport used unless SetTransport is called
+const defaultTransport = TransportNative
+
+// errSocketIOUnavailable is returned when TransportSocketIO is selected in a
+// build without the socket.io client library
+var errSocketIOUnavailable = errors.New("socket.io transport is not available in builds with the pi42_nosocketio tag; use TransportNative")
+
+// newSocketIOTransport reports that the socket.io transport was left out
+func newSocketIOTransport(string, func()) (streamTransport, error) {
+	return nil, errSocketIOUnavailable
+}
+
+// newUserStreamTransport creates a native connection to the authenticated
+// user stream at serverURL. It reports whether the transport reconnects by
+// itself after the connection drops, which the native transport does not.
+func newUserStreamTransport(serverURL string) (streamTransport, bool) {
+	url := strings.Replace(serverURL, "https://", "wss://", 1)
+	return newNativeTransport(strings.TrimSuffix(url, "/")+"/?EIO=4&transport=websocket", nil, true), false
+}
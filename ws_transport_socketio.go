@@ -0,0 +1,105 @@
+//go:build !pi42_nosocketio
+
+package pi42
+
+import (
+	"github.com/zishang520/engine.io-client-go/transports"
+	"github.com/zishang520/engine.io/v2/types"
+	"github.com/zishang520/socket.io-client-go/socket"
+)
+
+// defaultTransport is the transport used unless SetTransport is called
+const defaultTransport = TransportSocketIO
+
+// socketIOTransport is the streamTransport backed by the socket.io client library
+type socketIOTransport struct {
+	manager *socket.Manager
+	io      *socket.Socket
+}
+
+// newSocketIOTransport creates a socket.io connection to serverURL. onPing is
+// invoked for every heartbeat received from the server. The library always
+// offers permessage-deflate on the WebSocket transport, so compression
+// cannot be disabled here; Connect uses the native transport instead.
+func newSocketIOTransport(serverURL string, onPing func()) (streamTransport, error) {
+	opts := socket.DefaultOptions()
+	opts.SetTransports(types.NewSet(transports.Polling, transports.WebSocket))
+	opts.SetPerMessageDeflate(&types.PerMessageDeflate{Threshold: compressionThreshold})
+	// Reconnects follow the client's ReconnectPolicy instead of the
+	// manager's fixed backoff
+	opts.SetReconnection(false)
+	// Connect is called once the handlers are registered
+	opts.SetAutoConnect(false)
+
+	manager := socket.NewManager(serverURL, opts)
+
+	// Listening to manager events
+	manager.On("error", func(errs ...any) {
+		logger().Warnf("Manager Error: %v", errs)
+	})
+
+	manager.On("ping", func(...any) {
+		onPing()
+	})
+
+	// Using default namespace
+	return &socketIOTransport{
+		manager: manager,
+		io:      manager.Socket("/", opts),
+	}, nil
+}
+
+// newUserStreamTransport creates a connection to the authenticated user
+// stream at serverURL. It reports whether the transport reconnects by
+// itself after the connection drops.
+func newUserStreamTransport(serverURL string) (streamTransport, bool) {
+	opts := socket.DefaultOptions()
+	opts.SetPath("/")
+	opts.SetTransports(types.NewSet(transports.WebSocket, transports.Polling))
+	// Connect is called once the handlers are registered
+	opts.SetAutoConnect(false)
+
+	manager := socket.NewManager(serverURL, opts)
+	return &socketIOTransport{
+		manager: manager,
+		io:      manager.Socket("/", opts),
+	}, true
+}
+
+func (t *socketIOTransport) On(event EventName, handler func(...any)) {
+	t.io.On(types.EventName(event), handler)
+}
+
+func (t *socketIOTransport) OnAny(handler func(...any)) {
+	t.io.OnAny(handler)
+}
+
+func (t *socketIOTransport) Connect() {
+	t.io.Connect()
+}
+
+func (t *socketIOTransport) Connected() bool {
+	return t.io.Connected()
+}
+
+func (t *socketIOTransport) Disconnect() {
+	t.io.Disconnect()
+}
+
+func (t *socketIOTransport) Drop() {
+	if engine := t.manager.Engine(); engine != nil {
+		engine.Close()
+	}
+}
+
+func (t *socketIOTransport) Emit(event string, args ...any) error {
+	return t.io.Emit(event, args...)
+}
+
+func (t *socketIOTransport) EmitWithAck(event string, ack func([]any, error), args ...any) {
+	t.io.EmitWithAck(event, args...)(ack)
+}
+
+func (t *socketIOTransport) ID() string {
+	return t.io.Id()
+}
@@ -0,0 +1,134 @@
+package pi42
+
+import (
+	"strconv"
+	"time"
+)
+
+// DepthUpdateEvent represents a depthUpdate WebSocket event
+type DepthUpdateEvent struct {
+	DepthData
+}
+
+// MarkPriceEvent represents a markPriceUpdate WebSocket event
+type MarkPriceEvent struct {
+	EventType       string `json:"e"` // Event type (markPriceUpdate)
+	EventTime       int64  `json:"E"` // Event time in milliseconds
+	Symbol          string `json:"s"` // Trading pair symbol
+	MarkPrice       string `json:"p"` // Mark price
+	IndexPrice      string `json:"i"` // Index price
+	FundingRate     string `json:"r"` // Current funding rate
+	NextFundingTime int64  `json:"T"` // Next funding time in milliseconds
+}
+
+// KlineEvent represents a kline WebSocket event
+type KlineEvent struct {
+	EventType string           `json:"e"` // Event type (kline)
+	EventTime int64            `json:"E"` // Event time in milliseconds
+	Symbol    string           `json:"s"` // Trading pair symbol
+	Kline     KlineEventCandle `json:"k"` // Candle data
+}
+
+// KlineEventCandle represents the candle carried by a kline event
+type KlineEventCandle struct {
+	StartTime int64  `json:"t"` // Candle start time in milliseconds
+	EndTime   int64  `json:"T"` // Candle close time in milliseconds
+	Symbol    string `json:"s"` // Trading pair symbol
+	Interval  string `json:"i"` // Kline interval
+	Open      string `json:"o"` // Open price
+	Close     string `json:"c"` // Close price
+	High      string `json:"h"` // High price
+	Low       string `json:"l"` // Low price
+	Volume    string `json:"v"` // Base asset volume
+	IsClosed  bool   `json:"x"` // Whether the candle is closed
+}
+
+// KlineData converts the event candle into the REST representation
+func (e KlineEvent) KlineData() KlineData {
+	return KlineData{
+		StartTime: strconv.FormatInt(e.Kline.StartTime, 10),
+		Open:      e.Kline.Open,
+		High:      e.Kline.High,
+		Low:       e.Kline.Low,
+		Close:     e.Kline.Close,
+		EndTime:   strconv.FormatInt(e.Kline.EndTime, 10),
+		Volume:    e.Kline.Volume,
+	}
+}
+
+// TickerEvent represents a 24hrTicker WebSocket event
+type TickerEvent struct {
+	Ticker
+}
+
+// AggTradeEvent represents an aggTrade WebSocket event
+type AggTradeEvent struct {
+	EventType    string `json:"e"` // Event type (aggTrade)
+	EventTime    int64  `json:"E"` // Event time in milliseconds
+	TradeID      int64  `json:"a"` // Aggregate trade ID
+	Symbol       string `json:"s"` // Trading pair symbol
+	Price        string `json:"p"` // Trade price
+	Quantity     string `json:"q"` // Trade quantity
+	FirstTradeID int64  `json:"f"` // First trade ID in the aggregate
+	LastTradeID  int64  `json:"l"` // Last trade ID in the aggregate
+	TradeTime    int64  `json:"T"` // Trade time in milliseconds
+	IsBuyerMaker bool   `json:"m"` // Whether the buyer was the maker
+}
+
+// Trade converts the event into a typed Trade
+func (e AggTradeEvent) Trade() (Trade, error) {
+	price, err := strconv.ParseFloat(e.Price, 64)
+	if err != nil {
+		return Trade{}, err
+	}
+	quantity, err := strconv.ParseFloat(e.Quantity, 64)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	side := OrderSideBuy
+	if e.IsBuyerMaker {
+		side = OrderSideSell
+	}
+
+	return Trade{
+		ID:       e.TradeID,
+		Symbol:   e.Symbol,
+		Price:    price,
+		Quantity: quantity,
+		Side:     side,
+		Time:     time.UnixMilli(e.TradeTime),
+	}, nil
+}
+
+// decodeTypedPayload decodes the payload of known events into their typed
+// structures. It returns nil for events without a typed representation or
+// payloads that cannot be decoded.
+func decodeTypedPayload(event EventName, data []any) any {
+	var payload any
+	switch event {
+	case "depthUpdate":
+		payload = &DepthUpdateEvent{}
+	case "markPriceUpdate":
+		payload = &MarkPriceEvent{}
+	case "kline":
+		payload = &KlineEvent{}
+	case "24hrTicker":
+		payload = &TickerEvent{}
+	case "aggTrade":
+		payload = &AggTradeEvent{}
+	case "tickerArr":
+		tickers, err := decodeTickerArr(data)
+		if err != nil {
+			return nil
+		}
+		return tickers
+	default:
+		return nil
+	}
+
+	if err := decodeEventPayload(data, payload); err != nil {
+		return nil
+	}
+	return payload
+}